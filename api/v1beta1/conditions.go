@@ -0,0 +1,164 @@
+package v1beta1
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// condition types set on all resources, they follow the conventions of kstatus so that GitOps tools can determine
+// the health of the resources
+const (
+	// the resource is synced to all matching instances, for a Grafana: the instance is installed
+	ConditionReady = "Ready"
+	// the last reconcile succeeded
+	ConditionSynced = "Synced"
+	// the resource can't be reconciled until it is changed
+	ConditionStalled = "Stalled"
+	// the health checks of a datasource succeeded in all instances
+	ConditionHealthy = "Healthy"
+)
+
+// reasons of the conditions
+const (
+	ReasonSynced              = "Synced"
+	ReasonSyncFailed          = "SyncFailed"
+	ReasonInProgress          = "InProgress"
+	ReasonNoMatchingInstances = "NoMatchingInstances"
+	ReasonInstancesNotReady   = "InstancesNotReady"
+	ReasonUidConflict         = "UidConflict"
+	ReasonUnsupported         = "Unsupported"
+	ReasonHealthCheckPassed   = "HealthCheckPassed"
+	ReasonHealthCheckFailed   = "HealthCheckFailed"
+)
+
+// setSyncConditions sets the Ready, Synced and Stalled conditions. Ready and Synced are true when the reason is
+// ReasonSynced, otherwise the message explains why they are false.
+func setSyncConditions(conditions *[]metav1.Condition, generation int64, reason string, message string, stalled bool) {
+	status := metav1.ConditionFalse
+	if reason == ReasonSynced {
+		status = metav1.ConditionTrue
+	}
+
+	// the resource may be synced even though it isn't ready yet, for example while instances are starting
+	synced := status
+	syncedReason := reason
+	if reason == ReasonNoMatchingInstances || reason == ReasonInstancesNotReady || reason == ReasonInProgress {
+		synced = metav1.ConditionTrue
+		syncedReason = ReasonSynced
+	}
+
+	stalledStatus := metav1.ConditionFalse
+	if stalled {
+		stalledStatus = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ConditionReady,
+		Status:             status,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ConditionSynced,
+		Status:             synced,
+		ObservedGeneration: generation,
+		Reason:             syncedReason,
+		Message:            getSyncedMessage(synced, message),
+	})
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               ConditionStalled,
+		Status:             stalledStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            getStalledMessage(stalled, message),
+	})
+}
+
+func getSyncedMessage(synced metav1.ConditionStatus, message string) string {
+	if synced == metav1.ConditionTrue {
+		return ""
+	}
+	return message
+}
+
+func getStalledMessage(stalled bool, message string) string {
+	if !stalled {
+		return ""
+	}
+	return message
+}
+
+// SetSyncConditions sets the conditions and the observed generation of the instance
+func (in *GrafanaStatus) SetSyncConditions(generation int64, reason string, message string) {
+	in.ObservedGeneration = generation
+	setSyncConditions(&in.Conditions, generation, reason, message, false)
+}
+
+// SetSyncConditions sets the conditions and the observed generation of the dashboard
+func (in *GrafanaDashboardStatus) SetSyncConditions(generation int64, reason string, message string, stalled bool) {
+	in.ObservedGeneration = generation
+	setSyncConditions(&in.Conditions, generation, reason, message, stalled)
+}
+
+// SetSyncConditions sets the conditions and the observed generation of the datasource
+func (in *GrafanaDatasourceStatus) SetSyncConditions(generation int64, reason string, message string) {
+	in.ObservedGeneration = generation
+	setSyncConditions(&in.Conditions, generation, reason, message, false)
+}
+
+// SetSyncConditions sets the conditions and the observed generation of the folder
+func (in *GrafanaFolderStatus) SetSyncConditions(generation int64, reason string, message string, stalled bool) {
+	in.ObservedGeneration = generation
+	setSyncConditions(&in.Conditions, generation, reason, message, stalled)
+}
+
+// SetSyncConditions sets the conditions and the observed generation of the library panel
+func (in *GrafanaLibraryPanelStatus) SetSyncConditions(generation int64, reason string, message string, stalled bool) {
+	in.ObservedGeneration = generation
+	setSyncConditions(&in.Conditions, generation, reason, message, stalled)
+}
+
+// SetSyncConditions sets the conditions and the observed generation of the dashboard export
+func (in *GrafanaDashboardExportStatus) SetSyncConditions(generation int64, reason string, message string) {
+	in.ObservedGeneration = generation
+	setSyncConditions(&in.Conditions, generation, reason, message, false)
+}
+
+// SetSyncConditions sets the conditions and the observed generation of the datasource template
+func (in *GrafanaDatasourceTemplateStatus) SetSyncConditions(generation int64, reason string, message string) {
+	in.ObservedGeneration = generation
+	setSyncConditions(&in.Conditions, generation, reason, message, false)
+}
+
+// SetHealthCondition sets the Healthy condition from the results of the health checks, the condition is removed when
+// there are no results
+func (in *GrafanaDatasourceStatus) SetHealthCondition(generation int64) {
+	if len(in.Health) == 0 {
+		meta.RemoveStatusCondition(&in.Conditions, ConditionHealthy)
+		return
+	}
+
+	var failed []string
+	for _, health := range in.Health {
+		if health.Status != DatasourceHealthOK {
+			failed = append(failed, fmt.Sprintf("%v: %v", health.Instance, health.Message))
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionHealthy,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             ReasonHealthCheckPassed,
+	}
+	if len(failed) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonHealthCheckFailed
+		condition.Message = strings.Join(failed, "; ")
+	}
+	meta.SetStatusCondition(&in.Conditions, condition)
+}
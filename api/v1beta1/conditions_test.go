@@ -0,0 +1,63 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetSyncConditions(t *testing.T) {
+	status := &GrafanaDashboardStatus{}
+
+	status.SetSyncConditions(2, ReasonSynced, "", false)
+	assert.Equal(t, int64(2), status.ObservedGeneration)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionReady))
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionSynced))
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, ConditionStalled))
+
+	// instances that are not ready yet don't fail the sync
+	status.SetSyncConditions(3, ReasonInstancesNotReady, "waiting for instances grafana/grafana", false)
+	ready := meta.FindStatusCondition(status.Conditions, ConditionReady)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, ReasonInstancesNotReady, ready.Reason)
+	assert.Equal(t, int64(3), ready.ObservedGeneration)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionSynced))
+
+	status.SetSyncConditions(3, ReasonSyncFailed, "grafana/grafana: status: 500", false)
+	synced := meta.FindStatusCondition(status.Conditions, ConditionSynced)
+	assert.Equal(t, metav1.ConditionFalse, synced.Status)
+	assert.Equal(t, "grafana/grafana: status: 500", synced.Message)
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, ConditionStalled))
+
+	status.SetSyncConditions(4, ReasonUidConflict, "uid overview is already used", true)
+	stalled := meta.FindStatusCondition(status.Conditions, ConditionStalled)
+	assert.Equal(t, metav1.ConditionTrue, stalled.Status)
+	assert.Equal(t, ReasonUidConflict, stalled.Reason)
+	assert.Len(t, status.Conditions, 3)
+}
+
+func TestSetHealthCondition(t *testing.T) {
+	status := &GrafanaDatasourceStatus{
+		Health: []GrafanaDatasourceHealth{
+			{Instance: "grafana/a", Status: DatasourceHealthOK},
+			{Instance: "grafana/b", Status: DatasourceHealthOK},
+		},
+	}
+
+	status.SetHealthCondition(1)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionHealthy))
+
+	status.Health[1] = GrafanaDatasourceHealth{Instance: "grafana/b", Status: DatasourceHealthError, Message: "connection refused"}
+	status.SetHealthCondition(1)
+	healthy := meta.FindStatusCondition(status.Conditions, ConditionHealthy)
+	assert.Equal(t, metav1.ConditionFalse, healthy.Status)
+	assert.Equal(t, ReasonHealthCheckFailed, healthy.Reason)
+	assert.Equal(t, "grafana/b: connection refused", healthy.Message)
+
+	// the condition is removed once health checks are off
+	status.Health = nil
+	status.SetHealthCondition(2)
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionHealthy))
+}
@@ -0,0 +1,14 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// isAllowCrossNamespaceImport returns true if a resource can be imported into instances in other namespaces, a
+// namespace selector implies cross namespace imports, unless they are disabled explicitly
+func isAllowCrossNamespaceImport(allow *bool, selector *metav1.LabelSelector) bool {
+	if allow != nil {
+		return *allow
+	}
+	return selector != nil
+}
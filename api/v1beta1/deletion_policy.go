@@ -0,0 +1,21 @@
+package v1beta1
+
+// FinalizerName is set on content resources, their content is removed from the instances before they are deleted
+const FinalizerName = "grafana.integreatly.org/finalizer"
+
+// +kubebuilder:validation:Enum=Delete;Retain
+type DeletionPolicy string
+
+const (
+	// the content is removed from all instances it was synced to when the resource is deleted
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	// the content is kept in the instances when the resource is deleted and no longer managed by the operator
+	DeletionPolicyRetain DeletionPolicy = "Retain"
+)
+
+func getDeletionPolicy(policy DeletionPolicy) DeletionPolicy {
+	if policy == "" {
+		return DeletionPolicyDelete
+	}
+	return policy
+}
@@ -33,6 +33,7 @@ const (
 	OperatorStageService        OperatorStageName = "service"
 	OperatorStageIngress        OperatorStageName = "ingress"
 	OperatorStagePlugins        OperatorStageName = "plugins"
+	OperatorStageProvisioning   OperatorStageName = "provisioning"
 	OperatorStageDeployment     OperatorStageName = "deployment"
 	OperatorStageComplete       OperatorStageName = "complete"
 )
@@ -50,8 +51,20 @@ type OperatorReconcileVars struct {
 
 	// env var value for installed plugins
 	Plugins string
+
+	// used to restart the Grafana container when provisioned datasources change
+	DatasourcesHash string
 }
 
+type ProvisioningMode string
+
+const (
+	// dashboards and datasources are imported through the Grafana api
+	ProvisioningModeApi ProvisioningMode = "api"
+	// dashboards and datasources are rendered into provisioning files mounted into the Grafana container
+	ProvisioningModeFile ProvisioningMode = "file"
+)
+
 // GrafanaSpec defines the desired state of Grafana
 type GrafanaSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -65,6 +78,11 @@ type GrafanaSpec struct {
 	Client                *GrafanaClient               `json:"client,omitempty"`
 	Jsonnet               *JsonnetConfig               `json:"jsonnet,omitempty"`
 	External              *External                    `json:"external,omitempty"`
+	// how dashboards and datasources are provisioned in the instance: imported through the api, or rendered into
+	// provisioning files that survive restarts of the Grafana pod. Defaults to api, external instances always use the api
+	// +kubebuilder:validation:Enum=api;file
+	// +optional
+	ProvisioningMode ProvisioningMode `json:"provisioningMode,omitempty"`
 }
 
 type External struct {
@@ -88,17 +106,30 @@ type GrafanaClient struct {
 
 // GrafanaStatus defines the observed state of Grafana
 type GrafanaStatus struct {
-	Stage       OperatorStageName      `json:"stage,omitempty"`
-	StageStatus OperatorStageStatus    `json:"stageStatus,omitempty"`
-	LastMessage string                 `json:"lastMessage,omitempty"`
-	AdminUrl    string                 `json:"adminUrl,omitempty"`
-	Dashboards  NamespacedResourceList `json:"dashboards,omitempty"`
-	Datasources NamespacedResourceList `json:"datasources,omitempty"`
-	Folders     NamespacedResourceList `json:"folders,omitempty"`
+	Stage         OperatorStageName      `json:"stage,omitempty"`
+	StageStatus   OperatorStageStatus    `json:"stageStatus,omitempty"`
+	LastMessage   string                 `json:"lastMessage,omitempty"`
+	AdminUrl      string                 `json:"adminUrl,omitempty"`
+	Dashboards    NamespacedResourceList `json:"dashboards,omitempty"`
+	Datasources   NamespacedResourceList `json:"datasources,omitempty"`
+	Folders       NamespacedResourceList `json:"folders,omitempty"`
+	LibraryPanels NamespacedResourceList `json:"libraryPanels,omitempty"`
+	// generation of the resource the conditions were computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Ready, Synced and Stalled conditions of the resource
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Stage",type="string",JSONPath=".status.stage"
+//+kubebuilder:printcolumn:name="Stage status",type="string",JSONPath=".status.stageStatus"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Grafana is the Schema for the grafanas API
 type Grafana struct {
@@ -132,3 +163,8 @@ func (in *Grafana) IsInternal() bool {
 func (in *Grafana) IsExternal() bool {
 	return in.Spec.External != nil
 }
+
+// IsFileProvisioning returns true if dashboards and datasources are provisioned from files instead of the api
+func (in *Grafana) IsFileProvisioning() bool {
+	return in.IsInternal() && in.Spec.ProvisioningMode == ProvisioningModeFile
+}
@@ -24,22 +24,154 @@ import (
 	"io"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type DashboardSourceType string
 
+// +kubebuilder:validation:Enum=Revert;Report;Ignore
+type DriftPolicy string
+
+const (
+	// changes made in Grafana are overwritten with the dashboard of the CR
+	DriftPolicyRevert DriftPolicy = "Revert"
+	// changes made in Grafana are kept and reported in the status and as events
+	DriftPolicyReport DriftPolicy = "Report"
+	// changes made in Grafana are not checked for
+	DriftPolicyIgnore DriftPolicy = "Ignore"
+)
+
 const (
-	DashboardSourceTypeRawJson  DashboardSourceType = "json"
-	DashboardSourceTypeGzipJson DashboardSourceType = "gzipJson"
-	DashboardSourceTypeUrl      DashboardSourceType = "url"
-	DashboardSourceTypeJsonnet  DashboardSourceType = "jsonnet"
-	DefaultResyncPeriod                             = "5m"
+	DashboardSourceTypeRawJson        DashboardSourceType = "json"
+	DashboardSourceTypeGzipJson       DashboardSourceType = "gzipJson"
+	DashboardSourceTypeUrl            DashboardSourceType = "url"
+	DashboardSourceTypeJsonnet        DashboardSourceType = "jsonnet"
+	DashboardSourceTypeConfigMap      DashboardSourceType = "configMap"
+	DashboardSourceTypeSecret         DashboardSourceType = "secret"
+	DashboardSourceTypeGrafanaCom     DashboardSourceType = "grafanaCom"
+	DashboardSourceTypeJsonnetProject DashboardSourceType = "jsonnetProject"
+	DefaultResyncPeriod                                   = "5m"
 )
 
+// GrafanaComDashboardReference references a dashboard published on grafana.com
+type GrafanaComDashboardReference struct {
+	// id of the dashboard on grafana.com
+	Id int `json:"id"`
+
+	// revision to import, if not set the latest revision is resolved whenever the content cache expires
+	// +optional
+	Revision *int `json:"revision,omitempty"`
+}
+
+// JsonnetVariable is an external variable or top-level argument passed to jsonnet
+type JsonnetVariable struct {
+	Name string `json:"name"`
+
+	// literal value of the variable
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// source of the value of the variable
+	// +optional
+	ValueFrom *JsonnetVariableSource `json:"valueFrom,omitempty"`
+
+	// evaluate the value as jsonnet code instead of passing it as a string
+	// +optional
+	Code bool `json:"code,omitempty"`
+}
+
+// JsonnetVariableSource selects a key of a config map or a secret in the namespace of the dashboard
+type JsonnetVariableSource struct {
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// +optional
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// JsonnetProject is a jsonnet project of multiple files packaged as a gzipped tarball. Exactly one source of the
+// archive must be set.
+type JsonnetProject struct {
+	// path of the file inside the archive that is evaluated
+	Entrypoint string `json:"entrypoint"`
+
+	// the gzipped tarball of the project. Base64-encoded when in YAML.
+	// +optional
+	GzipTar []byte `json:"gzipTar,omitempty"`
+
+	// the gzipped tarball from a config map key in the same namespace
+	// +optional
+	ConfigMapRef *v1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+
+	// url the gzipped tarball is downloaded from, cached like dashboards fetched from urls
+	// +optional
+	Url string `json:"url,omitempty"`
+}
+
+// GrafanaDashboardUrlAuthorization holds the credentials sent with requests against the dashboard url
+type GrafanaDashboardUrlAuthorization struct {
+	// basic auth credentials
+	// +optional
+	BasicAuth *GrafanaDashboardUrlBasicAuth `json:"basicAuth,omitempty"`
+
+	// token sent as bearer token in the Authorization header
+	// +optional
+	BearerToken *v1.SecretKeySelector `json:"bearerToken,omitempty"`
+
+	// additional headers with values from secrets
+	// +optional
+	Headers []GrafanaDashboardUrlHeader `json:"headers,omitempty"`
+}
+
+type GrafanaDashboardUrlBasicAuth struct {
+	Username *v1.SecretKeySelector `json:"username"`
+	Password *v1.SecretKeySelector `json:"password"`
+}
+
+type GrafanaDashboardUrlHeader struct {
+	Name      string                `json:"name"`
+	ValueFrom *v1.SecretKeySelector `json:"valueFrom"`
+}
+
+// CaBundleSource selects a key of a config map or a secret holding PEM encoded certificates
+type CaBundleSource struct {
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+
+	// +optional
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
 type GrafanaDashboardDatasource struct {
-	InputName      string `json:"inputName"`
-	DatasourceName string `json:"datasourceName"`
+	InputName string `json:"inputName"`
+
+	// name of the datasource, replaces ${inputName} in the dashboard json
+	// +optional
+	DatasourceName string `json:"datasourceName,omitempty"`
+
+	// GrafanaDatasource the input is resolved to, datasource references to ${inputName} are replaced with the uid and
+	// type of the datasource in each instance
+	// +optional
+	DatasourceRef *GrafanaDatasourceReference `json:"datasourceRef,omitempty"`
+}
+
+// GrafanaFolderReference references a GrafanaFolder by name
+type GrafanaFolderReference struct {
+	Name string `json:"name"`
+
+	// namespace of the GrafanaFolder, defaults to the namespace of the dashboard
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GrafanaDatasourceReference references a GrafanaDatasource by name
+type GrafanaDatasourceReference struct {
+	Name string `json:"name"`
+
+	// namespace of the GrafanaDatasource, defaults to the namespace of the dashboard
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -51,6 +183,13 @@ type GrafanaDashboardSpec struct {
 	// +optional
 	Json string `json:"json,omitempty"`
 
+	// uid of the dashboard in the instances, defaults to the uid in the dashboard json and then to the uid of the CR.
+	// The dashboard is moved to the new uid when it changes
+	// +optional
+	// +kubebuilder:validation:MaxLength=40
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_-]*$`
+	Uid string `json:"uid,omitempty"`
+
 	// GzipJson the dashboard's JSON compressed with Gzip. Base64-encoded when in YAML.
 	// +optional
 	GzipJson []byte `json:"gzipJson,omitempty"`
@@ -59,10 +198,42 @@ type GrafanaDashboardSpec struct {
 	// +optional
 	Url string `json:"url,omitempty"`
 
+	// credentials used to fetch the dashboard url and the url of a jsonnet project, read from secrets in the same namespace
+	// +optional
+	UrlAuthorization *GrafanaDashboardUrlAuthorization `json:"urlAuthorization,omitempty"`
+
+	// certificates used to verify the server when fetching urls, the system roots are used if not set
+	// +optional
+	UrlCaBundle *CaBundleSource `json:"urlCaBundle,omitempty"`
+
 	// Jsonnet
 	// +optional
 	Jsonnet string `json:"jsonnet,omitempty"`
 
+	// external variables passed to jsonnet
+	// +optional
+	JsonnetExtVars []JsonnetVariable `json:"jsonnetExtVars,omitempty"`
+
+	// top-level arguments passed to jsonnet
+	// +optional
+	JsonnetTLAs []JsonnetVariable `json:"jsonnetTLAs,omitempty"`
+
+	// jsonnet project of multiple files
+	// +optional
+	JsonnetProject *JsonnetProject `json:"jsonnetProject,omitempty"`
+
+	// dashboard from a config map key in the same namespace
+	// +optional
+	ConfigMapRef *v1.ConfigMapKeySelector `json:"configMapRef,omitempty"`
+
+	// dashboard from a secret key in the same namespace
+	// +optional
+	SecretRef *v1.SecretKeySelector `json:"secretRef,omitempty"`
+
+	// dashboard from grafana.com
+	// +optional
+	GrafanaCom *GrafanaComDashboardReference `json:"grafanaCom,omitempty"`
+
 	// selects Grafanas for import
 	InstanceSelector *metav1.LabelSelector `json:"instanceSelector"`
 
@@ -70,6 +241,10 @@ type GrafanaDashboardSpec struct {
 	// +optional
 	FolderTitle string `json:"folder,omitempty"`
 
+	// GrafanaFolder the dashboard is imported to, can't be combined with folder
+	// +optional
+	FolderRef *GrafanaFolderReference `json:"folderRef,omitempty"`
+
 	// plugins
 	// +optional
 	Plugins PluginList `json:"plugins,omitempty"`
@@ -81,6 +256,18 @@ type GrafanaDashboardSpec struct {
 	// +optional
 	ResyncPeriod string `json:"resyncPeriod,omitempty"`
 
+	// what happens to the dashboard in the instances when the resource is deleted, defaults to Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// what to do when the dashboard was changed in Grafana, checked on every resync. Defaults to Ignore
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+
+	// permissions of the dashboard, replace the default permissions and are applied on every reconcile when set
+	// +optional
+	Permissions []Permission `json:"permissions,omitempty"`
+
 	// maps required data sources to existing ones
 	// +optional
 	Datasources []GrafanaDashboardDatasource `json:"datasources,omitempty"`
@@ -88,20 +275,70 @@ type GrafanaDashboardSpec struct {
 	// allow to import this resources from an operator in a different namespace
 	// +optional
 	AllowCrossNamespaceImport *bool `json:"allowCrossNamespaceImport,omitempty"`
+
+	// selects the namespaces of the Grafanas for import, instances in namespaces not matching the selector are ignored.
+	// Enables cross namespace imports, unless allowCrossNamespaceImport is set to false.
+	// +optional
+	InstanceNamespaceSelector *metav1.LabelSelector `json:"instanceNamespaceSelector,omitempty"`
 }
 
 // GrafanaDashboardStatus defines the observed state of GrafanaDashboard
 type GrafanaDashboardStatus struct {
-	ContentCache     []byte      `json:"contentCache,omitempty"`
-	ContentTimestamp metav1.Time `json:"contentTimestamp,omitempty"`
-	ContentUrl       string      `json:"contentUrl,omitempty"`
-	Hash             string      `json:"hash,omitempty"`
+	ContentCache []byte `json:"contentCache,omitempty"`
+	// config maps holding a content cache that is too large for the status
+	ContentCacheRef  *ContentCacheReference `json:"contentCacheRef,omitempty"`
+	ContentTimestamp metav1.Time            `json:"contentTimestamp,omitempty"`
+	ContentUrl       string                 `json:"contentUrl,omitempty"`
+	// ETag returned with the content cache, sent to revalidate the cache once it expired
+	ContentEtag string `json:"contentEtag,omitempty"`
+	// Last-Modified header returned with the content cache, sent to revalidate the cache once it expired
+	ContentLastModified string `json:"contentLastModified,omitempty"`
+	Hash                string `json:"hash,omitempty"`
+	// The revision of the dashboard imported from grafana.com
+	GrafanaComRevision int `json:"grafanaComRevision,omitempty"`
 	// The dashboard instanceSelector can't find matching grafana instances
 	NoMatchingInstances bool `json:"NoMatchingInstances,omitempty"`
+	// Instances in which the dashboard was changed outside of the operator
+	Drift []GrafanaDashboardDrift `json:"drift,omitempty"`
+	// Another dashboard already uses the uid of the dashboard in an instance, the dashboard isn't imported there
+	UidConflict string `json:"uidConflict,omitempty"`
+	// sync status of the resource in each matching instance
+	// +optional
+	Instances InstanceSyncStatusList `json:"instances,omitempty"`
+	// generation of the resource the conditions were computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Ready, Synced and Stalled conditions of the resource
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ContentCacheReference points to the config maps a large content cache is stored in
+type ContentCacheReference struct {
+	// names of the config maps holding the chunks of the gzipped content, in order
+	ConfigMaps []string `json:"configMaps"`
+	// sha256 of the gzipped content, chunks that don't match it are ignored
+	Hash string `json:"hash"`
+}
+
+// GrafanaDashboardDrift describes changes made to the dashboard in a Grafana instance
+type GrafanaDashboardDrift struct {
+	// namespace/name of the Grafana instance
+	Instance string `json:"instance"`
+	// version of the dashboard in the instance
+	Version int64 `json:"version,omitempty"`
+	// top level fields of the dashboard json that differ from the CR
+	ChangedFields []string `json:"changedFields,omitempty"`
+	// time the drift was first detected
+	DetectedAt metav1.Time `json:"detectedAt"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // GrafanaDashboard is the Schema for the grafanadashboards API
 type GrafanaDashboard struct {
@@ -122,13 +359,57 @@ type GrafanaDashboardList struct {
 }
 
 func (in *GrafanaDashboard) Hash() string {
+	return in.HashContent([]byte(in.Spec.Json))
+}
+
+// HashContent hashes the dashboard json fetched from any of the sources, together with the fields of the spec that
+// require the dashboard to be imported again
+func (in *GrafanaDashboard) HashContent(content []byte) string {
 	hash := sha256.New()
-	hash.Write([]byte(in.Spec.Json))
+	hash.Write(content)
+	if in.Spec.Uid != "" {
+		hash.Write([]byte(in.Spec.Uid))
+	}
+	// the dashboard has to be moved when the referenced folder changes
+	if in.Spec.FolderRef != nil {
+		hash.Write([]byte(fmt.Sprintf("%v/%v", in.GetFolderRefNamespace(), in.Spec.FolderRef.Name)))
+	}
 	return fmt.Sprintf("%x", hash.Sum(nil))
 }
 
-func (in *GrafanaDashboard) Unchanged() bool {
-	return in.Hash() == in.Status.Hash
+// GetUid returns the uid of the dashboard in the instances: spec.uid, the uid in the dashboard json or the uid of the CR
+func (in *GrafanaDashboard) GetUid(jsonUid string) string {
+	if in.Spec.Uid != "" {
+		return in.Spec.Uid
+	}
+	if jsonUid != "" {
+		return jsonUid
+	}
+	return string(in.UID)
+}
+
+func (in *GrafanaDashboard) GetDriftPolicy() DriftPolicy {
+	if in.Spec.DriftPolicy == "" {
+		return DriftPolicyIgnore
+	}
+	return in.Spec.DriftPolicy
+}
+
+func (in *GrafanaDashboard) GetDeletionPolicy() DeletionPolicy {
+	return getDeletionPolicy(in.Spec.DeletionPolicy)
+}
+
+// GetFolderRefNamespace returns the namespace of the referenced folder, which defaults to the namespace of the dashboard
+func (in *GrafanaDashboard) GetFolderRefNamespace() string {
+	if in.Spec.FolderRef == nil || in.Spec.FolderRef.Namespace == "" {
+		return in.Namespace
+	}
+	return in.Spec.FolderRef.Namespace
+}
+
+// Unchanged reports if the dashboard content hash matches the hash of the last import into the instance
+func (in *GrafanaDashboard) Unchanged(grafana *Grafana, hash string) bool {
+	return hash == in.Status.Instances.GetHash(grafana)
 }
 
 func (in *GrafanaDashboard) GetResyncPeriod() time.Duration {
@@ -165,11 +446,37 @@ func (in *GrafanaDashboard) GetSourceTypes() []DashboardSourceType {
 		sourceTypes = append(sourceTypes, DashboardSourceTypeJsonnet)
 	}
 
+	if in.Spec.ConfigMapRef != nil {
+		sourceTypes = append(sourceTypes, DashboardSourceTypeConfigMap)
+	}
+
+	if in.Spec.SecretRef != nil {
+		sourceTypes = append(sourceTypes, DashboardSourceTypeSecret)
+	}
+
+	if in.Spec.GrafanaCom != nil {
+		sourceTypes = append(sourceTypes, DashboardSourceTypeGrafanaCom)
+	}
+
+	if in.Spec.JsonnetProject != nil {
+		sourceTypes = append(sourceTypes, DashboardSourceTypeJsonnetProject)
+	}
+
 	return sourceTypes
 }
 
 func (in *GrafanaDashboard) GetContentCache() []byte {
-	return in.Status.getContentCache(in.Spec.Url, in.Spec.ContentCacheDuration.Duration)
+	return in.GetContentCacheForUrl(in.Spec.Url)
+}
+
+// GetContentCacheForUrl returns the content cache if it was populated from the given url
+func (in *GrafanaDashboard) GetContentCacheForUrl(url string) []byte {
+	return in.Status.getContentCache(url, in.Spec.ContentCacheDuration.Duration)
+}
+
+// GetStaleContentCacheForUrl returns the content cache populated from the given url, even if it expired
+func (in *GrafanaDashboard) GetStaleContentCacheForUrl(url string) []byte {
+	return in.Status.getContentCache(url, 0)
 }
 
 // getContentCache returns content cache when the following conditions are met: url is the same, data is not expired, gzipped data is not corrupted
@@ -192,10 +499,7 @@ func (in *GrafanaDashboardStatus) getContentCache(url string, cacheDuration time
 }
 
 func (in *GrafanaDashboard) IsAllowCrossNamespaceImport() bool {
-	if in.Spec.AllowCrossNamespaceImport != nil {
-		return *in.Spec.AllowCrossNamespaceImport
-	}
-	return false
+	return isAllowCrossNamespaceImport(in.Spec.AllowCrossNamespaceImport, in.Spec.InstanceNamespaceSelector)
 }
 
 func Gunzip(compressed []byte) ([]byte, error) {
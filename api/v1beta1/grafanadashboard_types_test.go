@@ -101,3 +101,96 @@ func Test_Gzip(t *testing.T) {
 
 	assert.Equal(t, dashboardJSON, decompressed, "Decompressed dashboard should match the original")
 }
+
+func TestGrafanaDashboard_HashIncludesFolderRef(t *testing.T) {
+	dashboard := &GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "grafana"},
+		Spec:       GrafanaDashboardSpec{Json: `{"title": "dashboard"}`},
+	}
+	withoutFolder := dashboard.Hash()
+
+	dashboard.Spec.FolderRef = &GrafanaFolderReference{Name: "folder"}
+	withFolder := dashboard.Hash()
+	assert.NotEqual(t, withoutFolder, withFolder)
+
+	// the namespace defaults to the namespace of the dashboard
+	dashboard.Spec.FolderRef.Namespace = "grafana"
+	assert.Equal(t, withFolder, dashboard.Hash())
+
+	dashboard.Spec.FolderRef.Namespace = "other"
+	assert.NotEqual(t, withFolder, dashboard.Hash())
+}
+
+func TestGrafanaDashboard_GetUid(t *testing.T) {
+	dashboard := &GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "grafana", UID: "cr-uid"},
+	}
+	assert.Equal(t, "cr-uid", dashboard.GetUid(""))
+	assert.Equal(t, "json-uid", dashboard.GetUid("json-uid"))
+
+	hash := dashboard.Hash()
+	dashboard.Spec.Uid = "spec-uid"
+	assert.Equal(t, "spec-uid", dashboard.GetUid("json-uid"))
+	assert.NotEqual(t, hash, dashboard.Hash())
+}
+
+func TestGrafanaDashboard_HashContent(t *testing.T) {
+	dashboard := &GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "grafana"},
+		Spec:       GrafanaDashboardSpec{Url: "http://example.com/dashboard.json"},
+	}
+	hash := dashboard.HashContent([]byte(`{"title": "dashboard"}`))
+	assert.NotEqual(t, dashboard.Hash(), hash)
+	assert.NotEqual(t, hash, dashboard.HashContent([]byte(`{"title": "changed"}`)))
+
+	// hashing the fetched content leaves the spec untouched
+	assert.Equal(t, "", dashboard.Spec.Json)
+	assert.Equal(t, dashboard.Hash(), dashboard.HashContent(nil))
+}
+
+func TestGrafanaDashboard_UnchangedPerInstance(t *testing.T) {
+	synced := &Grafana{ObjectMeta: metav1.ObjectMeta{Name: "synced", Namespace: "grafana"}}
+	outdated := &Grafana{ObjectMeta: metav1.ObjectMeta{Name: "outdated", Namespace: "grafana"}}
+
+	dashboard := &GrafanaDashboard{
+		Status: GrafanaDashboardStatus{
+			Hash: "new",
+			Instances: InstanceSyncStatusList{
+				{Instance: "grafana/synced", Hash: "new"},
+				{Instance: "grafana/outdated", Hash: "old"},
+			},
+		},
+	}
+	assert.True(t, dashboard.Unchanged(synced, "new"))
+	assert.False(t, dashboard.Unchanged(outdated, "new"))
+	assert.False(t, dashboard.Unchanged(&Grafana{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "grafana"}}, "new"))
+}
+
+func TestGrafanaDashboard_IsAllowCrossNamespaceImport(t *testing.T) {
+	allow := true
+	deny := false
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+
+	tests := []struct {
+		name     string
+		allow    *bool
+		selector *metav1.LabelSelector
+		want     bool
+	}{
+		{name: "default", want: false},
+		{name: "allowed", allow: &allow, want: true},
+		{name: "namespace selector", selector: selector, want: true},
+		{name: "namespace selector allowed", allow: &allow, selector: selector, want: true},
+		{name: "namespace selector denied", allow: &deny, selector: selector, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dashboard := &GrafanaDashboard{Spec: GrafanaDashboardSpec{
+				AllowCrossNamespaceImport: tt.allow,
+				InstanceNamespaceSelector: tt.selector,
+			}}
+			assert.Equal(t, tt.want, dashboard.IsAllowCrossNamespaceImport())
+		})
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GrafanaDashboardExportSpec defines the desired state of GrafanaDashboardExport
+type GrafanaDashboardExportSpec struct {
+	// selects the Grafanas dashboards are exported from
+	InstanceSelector *metav1.LabelSelector `json:"instanceSelector"`
+
+	// allow to export dashboards from instances in a different namespace
+	// +optional
+	AllowCrossNamespaceImport *bool `json:"allowCrossNamespaceImport,omitempty"`
+
+	// selects the namespaces of the Grafanas to export from, instances in namespaces not matching the selector are ignored.
+	// Enables cross namespace imports, unless allowCrossNamespaceImport is set to false.
+	// +optional
+	InstanceNamespaceSelector *metav1.LabelSelector `json:"instanceNamespaceSelector,omitempty"`
+
+	// name of the config map the exported dashboards are written to, defaults to the name of the export. Exports that
+	// exceed the size of a config map continue in config maps with the suffixes -1, -2 and so on
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// instance selector set in the exported dashboards, defaults to the instance selector of the export
+	// +optional
+	DashboardInstanceSelector *metav1.LabelSelector `json:"dashboardInstanceSelector,omitempty"`
+
+	// how often the dashboards are exported, defaults to 5m
+	// +optional
+	ResyncPeriod string `json:"resyncPeriod,omitempty"`
+}
+
+// GrafanaDashboardExportStatus defines the observed state of GrafanaDashboardExport
+type GrafanaDashboardExportStatus struct {
+	// number of dashboards written to the config maps in the last export
+	ExportedDashboards int `json:"exportedDashboards,omitempty"`
+	// config maps the exported dashboards are written to, large exports are split across several config maps
+	// +optional
+	ConfigMaps []string `json:"configMaps,omitempty"`
+	// hash of the exported manifests
+	// +optional
+	Hash string `json:"hash,omitempty"`
+	// time the exported dashboards last changed
+	LastExportTime metav1.Time `json:"lastExportTime,omitempty"`
+	// The export instanceSelector can't find matching grafana instances
+	NoMatchingInstances bool `json:"NoMatchingInstances,omitempty"`
+	// generation of the resource the conditions were computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Ready, Synced and Stalled conditions of the resource
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Exported",type="integer",JSONPath=".status.exportedDashboards"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// GrafanaDashboardExport is the Schema for the grafanadashboardexports API, it writes dashboards that are not managed
+// by the operator to a config map as GrafanaDashboard manifests
+type GrafanaDashboardExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GrafanaDashboardExportSpec   `json:"spec,omitempty"`
+	Status GrafanaDashboardExportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GrafanaDashboardExportList contains a list of GrafanaDashboardExport
+type GrafanaDashboardExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GrafanaDashboardExport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GrafanaDashboardExport{}, &GrafanaDashboardExportList{})
+}
+
+func (in *GrafanaDashboardExport) GetConfigMapName() string {
+	if in.Spec.ConfigMapName == "" {
+		return in.Name
+	}
+	return in.Spec.ConfigMapName
+}
+
+func (in *GrafanaDashboardExport) GetDashboardInstanceSelector() *metav1.LabelSelector {
+	if in.Spec.DashboardInstanceSelector == nil {
+		return in.Spec.InstanceSelector
+	}
+	return in.Spec.DashboardInstanceSelector
+}
+
+func (in *GrafanaDashboardExport) GetResyncPeriod() time.Duration {
+	duration, err := time.ParseDuration(in.Spec.ResyncPeriod)
+	if err != nil {
+		duration, _ = time.ParseDuration(DefaultResyncPeriod)
+	}
+	return duration
+}
+
+func (in *GrafanaDashboardExport) IsAllowCrossNamespaceImport() bool {
+	return isAllowCrossNamespaceImport(in.Spec.AllowCrossNamespaceImport, in.Spec.InstanceNamespaceSelector)
+}
@@ -22,8 +22,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -54,6 +57,52 @@ type GrafanaDatasourceInternal struct {
 	SecureJSONData json.RawMessage `json:"secureJsonData,omitempty"`
 }
 
+// GrafanaDatasourceValueFrom sets a field of the datasource to the value of a secret or config map key
+type GrafanaDatasourceValueFrom struct {
+	// dot separated path of the field the value is written to, for example secureJsonData.password or url. Values
+	// replacing a boolean or a number in the datasource are converted to its type, other fields are set as strings
+	// +kubebuilder:validation:MinLength=1
+	TargetPath string `json:"targetPath"`
+
+	ValueFrom GrafanaDatasourceValueFromSource `json:"valueFrom"`
+}
+
+// GrafanaDatasourceValueFromSource selects the key of a secret or config map, exactly one of them must be set
+type GrafanaDatasourceValueFromSource struct {
+	// +optional
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+
+	// +optional
+	ConfigMapKeyRef *v1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+}
+
+// GrafanaDatasourceHealthCheck configures the health checks of the datasource in the matching instances
+type GrafanaDatasourceHealthCheck struct {
+	// how often the health of the datasource is checked, defaults to 5m if not set
+	// +optional
+	Interval string `json:"interval,omitempty"`
+}
+
+const (
+	DatasourceHealthOK    = "OK"
+	DatasourceHealthError = "ERROR"
+)
+
+// GrafanaDatasourceHealth is the result of the last health check of the datasource in one of the matching instances
+type GrafanaDatasourceHealth struct {
+	// namespace/name of the Grafana instance
+	Instance string `json:"instance"`
+	// OK or ERROR
+	Status string `json:"status"`
+	// message returned by the health check
+	// +optional
+	Message string `json:"message,omitempty"`
+	// duration of the health check in milliseconds
+	LatencyMilliseconds int64 `json:"latencyMilliseconds"`
+	// time of the health check
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+}
+
 // GrafanaDatasourceSpec defines the desired state of GrafanaDatasource
 type GrafanaDatasourceSpec struct {
 	Datasource *GrafanaDatasourceInternal `json:"datasource,omitempty"`
@@ -65,10 +114,23 @@ type GrafanaDatasourceSpec struct {
 	// +optional
 	Plugins PluginList `json:"plugins,omitempty"`
 
-	// secrets used for variable expansion
+	// secrets used for variable expansion, prefer valuesFrom
 	// +optional
 	Secrets []string `json:"secrets,omitempty"`
 
+	// values of datasource fields taken from secrets or config maps in the namespace of the datasource
+	// +optional
+	ValuesFrom []GrafanaDatasourceValueFrom `json:"valuesFrom,omitempty"`
+
+	// checks that the instances can reach the datasource after every sync and on an interval, the datasource type
+	// has to support health checks
+	// +optional
+	HealthCheck *GrafanaDatasourceHealthCheck `json:"healthCheck,omitempty"`
+
+	// what happens to the datasource in the instances when the resource is deleted, defaults to Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// how often the datasource is refreshed, defaults to 24h if not set
 	// +optional
 	ResyncPeriod string `json:"resyncPeriod,omitempty"`
@@ -76,6 +138,11 @@ type GrafanaDatasourceSpec struct {
 	// allow to import this resources from an operator in a different namespace
 	// +optional
 	AllowCrossNamespaceImport *bool `json:"allowCrossNamespaceImport,omitempty"`
+
+	// selects the namespaces of the Grafanas for import, instances in namespaces not matching the selector are ignored.
+	// Enables cross namespace imports, unless allowCrossNamespaceImport is set to false.
+	// +optional
+	InstanceNamespaceSelector *metav1.LabelSelector `json:"instanceNamespaceSelector,omitempty"`
 }
 
 // GrafanaDatasourceStatus defines the observed state of GrafanaDatasource
@@ -84,10 +151,26 @@ type GrafanaDatasourceStatus struct {
 	LastMessage string `json:"lastMessage,omitempty"`
 	// The datasource instanceSelector can't find matching grafana instances
 	NoMatchingInstances bool `json:"NoMatchingInstances,omitempty"`
+	// sync status of the resource in each matching instance
+	// +optional
+	Instances InstanceSyncStatusList `json:"instances,omitempty"`
+	// results of the last health check in each instance the datasource is synced to
+	// +optional
+	Health []GrafanaDatasourceHealth `json:"health,omitempty"`
+	// generation of the resource the conditions were computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Ready, Synced, Stalled and Healthy conditions of the resource
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // GrafanaDatasource is the Schema for the grafanadatasources API
 type GrafanaDatasource struct {
@@ -125,6 +208,16 @@ func (in *GrafanaDatasource) Hash() string {
 			hash.Write([]byte(secret))
 		}
 
+		for _, value := range in.Spec.ValuesFrom {
+			hash.Write([]byte(value.TargetPath))
+			if ref := value.ValueFrom.SecretKeyRef; ref != nil {
+				hash.Write([]byte(fmt.Sprintf("secret/%v/%v", ref.Name, ref.Key)))
+			}
+			if ref := value.ValueFrom.ConfigMapKeyRef; ref != nil {
+				hash.Write([]byte(fmt.Sprintf("configmap/%v/%v", ref.Name, ref.Key)))
+			}
+		}
+
 		if in.Spec.Datasource.BasicAuth != nil && *in.Spec.Datasource.BasicAuth {
 			hash.Write([]byte("_"))
 		}
@@ -160,8 +253,60 @@ func (in *GrafanaDatasource) GetResyncPeriod() time.Duration {
 	return duration
 }
 
-func (in *GrafanaDatasource) Unchanged() bool {
-	return in.Hash() == in.Status.Hash
+func (in *GrafanaDatasource) GetDeletionPolicy() DeletionPolicy {
+	return getDeletionPolicy(in.Spec.DeletionPolicy)
+}
+
+// GetHealthCheckInterval returns how often the health of the datasource is checked, zero if health checks are off
+func (in *GrafanaDatasource) GetHealthCheckInterval() time.Duration {
+	if in.Spec.HealthCheck == nil {
+		return 0
+	}
+
+	duration, err := time.ParseDuration(in.Spec.HealthCheck.Interval)
+	if err != nil || duration <= 0 {
+		duration, _ = time.ParseDuration(DefaultResyncPeriod)
+	}
+	return duration
+}
+
+// GetRequeueAfter returns when the datasource is reconciled again after a successful sync, after the resync period or
+// when the next health check is due, whichever comes first
+func (in *GrafanaDatasource) GetRequeueAfter() time.Duration {
+	requeueAfter := in.GetResyncPeriod()
+	interval := in.GetHealthCheckInterval()
+	if interval <= 0 {
+		return requeueAfter
+	}
+
+	if interval < requeueAfter {
+		requeueAfter = interval
+	}
+	for _, health := range in.Status.Health {
+		due := time.Until(health.LastCheckTime.Add(interval))
+		if due < time.Second {
+			due = time.Second
+		}
+		if due < requeueAfter {
+			requeueAfter = due
+		}
+	}
+	return requeueAfter
+}
+
+// HashWithValues combines the hash of the spec with the digest of the secrets and config maps values are read from, it's
+// the hash recorded for each instance the datasource is synced to
+func (in *GrafanaDatasource) HashWithValues(valuesHash string) string {
+	hash := sha256.New()
+	hash.Write([]byte(in.Hash()))
+	hash.Write([]byte(valuesHash))
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// Unchanged reports if neither the spec nor the values read from secrets and config maps changed since the last sync
+// to the instance
+func (in *GrafanaDatasource) Unchanged(grafana *Grafana, hash string) bool {
+	return hash == in.Status.Instances.GetHash(grafana)
 }
 
 func (in *GrafanaDatasource) ExpandVariables(variables map[string][]byte) ([]byte, error) {
@@ -184,11 +329,96 @@ func (in *GrafanaDatasource) ExpandVariables(variables map[string][]byte) ([]byt
 	return raw, nil
 }
 
-func (in *GrafanaDatasource) IsAllowCrossNamespaceImport() bool {
-	if in.Spec.AllowCrossNamespaceImport != nil {
-		return *in.Spec.AllowCrossNamespaceImport
+// typedDatasourceFields are the fields of the datasource that aren't strings, they are omitted from the json while
+// they are unset
+var typedDatasourceFields = map[string]interface{}{
+	"orgId":     float64(0),
+	"isDefault": false,
+	"basicAuth": false,
+	"editable":  false,
+}
+
+// InjectValues writes the values of valuesFrom into the datasource json, values are keyed by their target path. The
+// values are set in the parsed datasource, so they can contain any character. Values replacing a boolean or a number
+// are converted to its type, other fields are set as strings.
+func (in *GrafanaDatasource) InjectValues(raw []byte, values map[string]string) ([]byte, error) {
+	if len(values) == 0 {
+		return raw, nil
+	}
+
+	var datasource map[string]interface{}
+	err := json.Unmarshal(raw, &datasource)
+	if err != nil {
+		return nil, err
+	}
+
+	for targetPath, value := range values {
+		if typed, ok := typedDatasourceFields[targetPath]; ok && datasource[targetPath] == nil {
+			datasource[targetPath] = typed
+		}
+
+		err = setValue(datasource, strings.Split(targetPath, "."), value)
+		if err != nil {
+			return nil, fmt.Errorf("can't set %v in datasource %v: %v", targetPath, in.Name, err)
+		}
+	}
+
+	return json.Marshal(datasource)
+}
+
+// setValue sets the field at the path, missing objects along the path are created. The value keeps the type of the
+// field it replaces.
+func setValue(object map[string]interface{}, path []string, value string) error {
+	if path[0] == "" {
+		return errors.New("empty field name")
+	}
+
+	if len(path) == 1 {
+		converted, err := convertValue(object[path[0]], value)
+		if err != nil {
+			return fmt.Errorf("%v: %v", path[0], err)
+		}
+		object[path[0]] = converted
+		return nil
+	}
+
+	child, ok := object[path[0]]
+	if !ok || child == nil {
+		child = map[string]interface{}{}
+		object[path[0]] = child
 	}
-	return false
+
+	childObject, ok := child.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%v is not an object", path[0])
+	}
+	return setValue(childObject, path[1:], value)
+}
+
+// convertValue converts the value to the type of the existing value, only strings, booleans and numbers can be set
+func convertValue(existing interface{}, value string) (interface{}, error) {
+	switch existing.(type) {
+	case nil, string:
+		return value, nil
+	case bool:
+		converted, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("expected a boolean, got %q", value)
+		}
+		return converted, nil
+	case float64:
+		converted, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", value)
+		}
+		return converted, nil
+	default:
+		return nil, errors.New("only strings, booleans and numbers can be set")
+	}
+}
+
+func (in *GrafanaDatasource) IsAllowCrossNamespaceImport() bool {
+	return isAllowCrossNamespaceImport(in.Spec.AllowCrossNamespaceImport, in.Spec.InstanceNamespaceSelector)
 }
 
 func (in *GrafanaDatasourceList) Find(namespace string, name string) *GrafanaDatasource {
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestGrafanaDatasources_expandVariables(t *testing.T) {
@@ -60,3 +63,142 @@ func TestGrafanaDatasources_expandVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestGrafanaDatasources_injectValues(t *testing.T) {
+	type testcase struct {
+		name   string
+		values map[string]string
+		in     []byte
+		out    []byte
+		err    bool
+	}
+
+	testcases := []testcase{
+		{
+			name:   "top level field",
+			values: map[string]string{"url": "http://prometheus:9090"},
+			in:     []byte(`{"name":"prometheus","url":"http://localhost"}`),
+			out:    []byte(`{"name":"prometheus","url":"http://prometheus:9090"}`),
+		},
+		{
+			name:   "nested field is created",
+			values: map[string]string{"secureJsonData.password": "secret"},
+			in:     []byte(`{"name":"prometheus"}`),
+			out:    []byte(`{"name":"prometheus","secureJsonData":{"password":"secret"}}`),
+		},
+		{
+			name:   "value is escaped",
+			values: map[string]string{"secureJsonData.password": `pa"ss\word ${VAR}`},
+			in:     []byte(`{"name":"prometheus","secureJsonData":{"other":"value"}}`),
+			out:    []byte(`{"name":"prometheus","secureJsonData":{"other":"value","password":"pa\"ss\\word ${VAR}"}}`),
+		},
+		{
+			name:   "path through a value",
+			values: map[string]string{"name.password": "secret"},
+			in:     []byte(`{"name":"prometheus"}`),
+			err:    true,
+		},
+		{
+			name:   "booleans and numbers keep their type",
+			values: map[string]string{"jsonData.tlsSkipVerify": "true", "jsonData.timeout": "30", "basicAuth": "true", "orgId": "2"},
+			in:     []byte(`{"jsonData":{"timeout":10,"tlsSkipVerify":false},"name":"prometheus"}`),
+			out:    []byte(`{"basicAuth":true,"jsonData":{"timeout":30,"tlsSkipVerify":true},"name":"prometheus","orgId":2}`),
+		},
+		{
+			name:   "invalid boolean",
+			values: map[string]string{"jsonData.tlsSkipVerify": "yes please"},
+			in:     []byte(`{"jsonData":{"tlsSkipVerify":false}}`),
+			err:    true,
+		},
+		{
+			name:   "invalid number",
+			values: map[string]string{"jsonData.timeout": "30s"},
+			in:     []byte(`{"jsonData":{"timeout":10}}`),
+			err:    true,
+		},
+		{
+			name:   "object target",
+			values: map[string]string{"jsonData": "{}"},
+			in:     []byte(`{"jsonData":{"timeout":10}}`),
+			err:    true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			datasource := GrafanaDatasource{}
+			b, err := datasource.InjectValues(tc.in, tc.values)
+			if tc.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(b, tc.out) {
+				t.Error(fmt.Errorf("expected %v, but got %v", string(tc.out), string(b)))
+			}
+		})
+	}
+}
+
+func TestGrafanaDatasources_getRequeueAfter(t *testing.T) {
+	datasource := GrafanaDatasource{Spec: GrafanaDatasourceSpec{ResyncPeriod: "1h"}}
+	if requeueAfter := datasource.GetRequeueAfter(); requeueAfter != time.Hour {
+		t.Errorf("expected the resync period, but got %v", requeueAfter)
+	}
+
+	datasource.Spec.HealthCheck = &GrafanaDatasourceHealthCheck{Interval: "30s"}
+	if requeueAfter := datasource.GetRequeueAfter(); requeueAfter != 30*time.Second {
+		t.Errorf("expected the health check interval, but got %v", requeueAfter)
+	}
+
+	datasource.Spec.HealthCheck.Interval = ""
+	if requeueAfter := datasource.GetRequeueAfter(); requeueAfter != 5*time.Minute {
+		t.Errorf("expected the default health check interval, but got %v", requeueAfter)
+	}
+}
+
+func TestGrafanaDatasources_unchanged(t *testing.T) {
+	datasource := GrafanaDatasource{Spec: GrafanaDatasourceSpec{Datasource: &GrafanaDatasourceInternal{Name: "prometheus"}}}
+	synced := &Grafana{}
+	synced.Namespace, synced.Name = "monitoring", "synced"
+	outdated := &Grafana{}
+	outdated.Namespace, outdated.Name = "monitoring", "outdated"
+
+	hash := datasource.HashWithValues("values")
+	datasource.Status.Instances = InstanceSyncStatusList{
+		{Instance: "monitoring/synced", Hash: hash},
+		{Instance: "monitoring/outdated", Hash: datasource.HashWithValues("previous values")},
+	}
+
+	if !datasource.Unchanged(synced, hash) {
+		t.Errorf("expected the datasource to be unchanged in the synced instance")
+	}
+	if datasource.Unchanged(outdated, hash) {
+		t.Errorf("expected the changed values to be applied to the outdated instance")
+	}
+}
+
+func TestGrafanaDatasources_getRequeueAfterNextHealthCheck(t *testing.T) {
+	datasource := GrafanaDatasource{Spec: GrafanaDatasourceSpec{
+		ResyncPeriod: "1h",
+		HealthCheck:  &GrafanaDatasourceHealthCheck{Interval: "1m"},
+	}}
+	datasource.Status.Health = []GrafanaDatasourceHealth{
+		{Instance: "monitoring/grafana", LastCheckTime: metav1.NewTime(time.Now().Add(-45 * time.Second))},
+	}
+
+	// the reconcile is scheduled for the next check instead of running the check again right away
+	if requeueAfter := datasource.GetRequeueAfter(); requeueAfter > 15*time.Second || requeueAfter < 10*time.Second {
+		t.Errorf("expected the time until the next health check, but got %v", requeueAfter)
+	}
+
+	datasource.Status.Health[0].LastCheckTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	if requeueAfter := datasource.GetRequeueAfter(); requeueAfter != time.Second {
+		t.Errorf("expected an overdue health check to be scheduled right away, but got %v", requeueAfter)
+	}
+}
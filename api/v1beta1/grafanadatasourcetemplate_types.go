@@ -0,0 +1,114 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GrafanaDatasourceTemplateSpec defines the desired state of GrafanaDatasourceTemplate
+type GrafanaDatasourceTemplateSpec struct {
+	// selects the namespaces a datasource is generated for
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector"`
+
+	// generates a datasource for every matching object in the selected namespaces instead of one per namespace
+	// +optional
+	Objects *GrafanaDatasourceTemplateObjects `json:"objects,omitempty"`
+
+	// spec of the generated datasources. String values of the datasource are Go templates, {{ .Namespace }} is
+	// replaced with the name of the namespace, {{ .Name }} with the name of the object or namespace and
+	// {{ .Labels.key }} with the value of its label key
+	Template GrafanaDatasourceSpec `json:"template"`
+
+	// how often the selected namespaces and objects are listed again, defaults to 5m if not set
+	// +optional
+	ResyncPeriod string `json:"resyncPeriod,omitempty"`
+}
+
+// GrafanaDatasourceTemplateObjects selects the objects datasources are generated for
+type GrafanaDatasourceTemplateObjects struct {
+	// api version of the objects, e.g. v1
+	APIVersion string `json:"apiVersion"`
+	// kind of the objects, e.g. ServiceAccount. The operator needs permissions to list them
+	Kind string `json:"kind"`
+	// selects the objects, all objects of the kind are selected if not set
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// GrafanaDatasourceTemplateStatus defines the observed state of GrafanaDatasourceTemplate
+type GrafanaDatasourceTemplateStatus struct {
+	// names of the generated GrafanaDatasources
+	// +optional
+	Datasources []string `json:"datasources,omitempty"`
+	LastMessage string   `json:"lastMessage,omitempty"`
+	// generation of the resource the conditions were computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Ready, Synced and Stalled conditions of the resource
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// GrafanaDatasourceTemplate is the Schema for the grafanadatasourcetemplates API, it generates a GrafanaDatasource
+// for every namespace or object matching its selectors
+type GrafanaDatasourceTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GrafanaDatasourceTemplateSpec   `json:"spec,omitempty"`
+	Status GrafanaDatasourceTemplateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GrafanaDatasourceTemplateList contains a list of GrafanaDatasourceTemplate
+type GrafanaDatasourceTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GrafanaDatasourceTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GrafanaDatasourceTemplate{}, &GrafanaDatasourceTemplateList{})
+}
+
+// GetDatasourceName returns the name of the GrafanaDatasource generated for a namespace or an object in a namespace,
+// long names are truncated
+func (in *GrafanaDatasourceTemplate) GetDatasourceName(object metav1.Object) string {
+	if object.GetNamespace() == "" {
+		return TruncateName(in.Name + "-" + object.GetName())
+	}
+	return TruncateName(in.Name + "-" + object.GetNamespace() + "-" + object.GetName())
+}
+
+func (in *GrafanaDatasourceTemplate) GetResyncPeriod() time.Duration {
+	duration, err := time.ParseDuration(in.Spec.ResyncPeriod)
+	if err != nil {
+		duration, _ = time.ParseDuration(DefaultResyncPeriod)
+	}
+	return duration
+}
@@ -19,6 +19,7 @@ package v1beta1
 import (
 	"crypto/sha256"
 	"fmt"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -36,6 +37,23 @@ type GrafanaFolderSpec struct {
 	// allow to import this resources from an operator in a different namespace
 	// +optional
 	AllowCrossNamespaceImport *bool `json:"allowCrossNamespaceImport,omitempty"`
+
+	// selects the namespaces of the Grafanas for import, instances in namespaces not matching the selector are ignored.
+	// Enables cross namespace imports, unless allowCrossNamespaceImport is set to false.
+	// +optional
+	InstanceNamespaceSelector *metav1.LabelSelector `json:"instanceNamespaceSelector,omitempty"`
+
+	// permissions of the folder, replace the default permissions and are applied on every reconcile when set
+	// +optional
+	Permissions []Permission `json:"permissions,omitempty"`
+
+	// what happens to the folder in the instances when the resource is deleted, defaults to Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// how often the folder is refreshed, defaults to 5m if not set
+	// +optional
+	ResyncPeriod string `json:"resyncPeriod,omitempty"`
 }
 
 // GrafanaFolderStatus defines the observed state of GrafanaFolder
@@ -45,10 +63,23 @@ type GrafanaFolderStatus struct {
 	Hash string `json:"hash,omitempty"`
 	// The folder instanceSelector can't find matching grafana instances
 	NoMatchingInstances bool `json:"NoMatchingInstances,omitempty"`
+	// sync status of the resource in each matching instance
+	// +optional
+	Instances InstanceSyncStatusList `json:"instances,omitempty"`
+	// generation of the resource the conditions were computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Ready, Synced and Stalled conditions of the resource
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // GrafanaFolder is the Schema for the grafanafolders API
 type GrafanaFolder struct {
@@ -87,13 +118,30 @@ func (in *GrafanaFolder) Hash() string {
 	return fmt.Sprintf("%x", hash.Sum(nil))
 }
 
-func (in *GrafanaFolder) Unchanged() bool {
-	return in.Hash() == in.Status.Hash
+// Unchanged reports if the folder was synced to the instance since the spec last changed
+func (in *GrafanaFolder) Unchanged(grafana *Grafana) bool {
+	return in.Hash() == in.Status.Instances.GetHash(grafana)
 }
 
 func (in *GrafanaFolder) IsAllowCrossNamespaceImport() bool {
-	if in.Spec.AllowCrossNamespaceImport != nil {
-		return *in.Spec.AllowCrossNamespaceImport
+	return isAllowCrossNamespaceImport(in.Spec.AllowCrossNamespaceImport, in.Spec.InstanceNamespaceSelector)
+}
+
+func (in *GrafanaFolder) GetDeletionPolicy() DeletionPolicy {
+	return getDeletionPolicy(in.Spec.DeletionPolicy)
+}
+
+func (in *GrafanaFolder) GetResyncPeriod() time.Duration {
+	if in.Spec.ResyncPeriod == "" {
+		in.Spec.ResyncPeriod = DefaultResyncPeriod
+		return in.GetResyncPeriod()
+	}
+
+	duration, err := time.ParseDuration(in.Spec.ResyncPeriod)
+	if err != nil {
+		in.Spec.ResyncPeriod = DefaultResyncPeriod
+		return in.GetResyncPeriod()
 	}
-	return false
+
+	return duration
 }
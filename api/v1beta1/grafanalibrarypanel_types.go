@@ -0,0 +1,235 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GrafanaLibraryPanelSpec defines the desired state of GrafanaLibraryPanel
+type GrafanaLibraryPanelSpec struct {
+	// panel json
+	// +optional
+	Json string `json:"json,omitempty"`
+
+	// GzipJson the panel's JSON compressed with Gzip. Base64-encoded when in YAML.
+	// +optional
+	GzipJson []byte `json:"gzipJson,omitempty"`
+
+	// panel url
+	// +optional
+	Url string `json:"url,omitempty"`
+
+	// Jsonnet
+	// +optional
+	Jsonnet string `json:"jsonnet,omitempty"`
+
+	// uid of the library panel in the instances, dashboards use it to reference the panel. Defaults to the uid in the
+	// panel json and then to the uid of the CR
+	// +optional
+	// +kubebuilder:validation:MaxLength=40
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_-]*$`
+	Uid string `json:"uid,omitempty"`
+
+	// selects Grafanas for import
+	InstanceSelector *metav1.LabelSelector `json:"instanceSelector"`
+
+	// reference to a GrafanaFolder the library panel is created in, defaults to the General folder
+	// +optional
+	FolderRef *GrafanaFolderReference `json:"folderRef,omitempty"`
+
+	// Cache duration for panels fetched from URLs
+	// +optional
+	ContentCacheDuration metav1.Duration `json:"contentCacheDuration,omitempty"`
+
+	// what happens to the library panel in the instances when the resource is deleted, defaults to Delete
+	// +optional
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// how often the library panel is refreshed, defaults to 5m if not set
+	// +optional
+	ResyncPeriod string `json:"resyncPeriod,omitempty"`
+
+	// allow to import this resources from an operator in a different namespace
+	// +optional
+	AllowCrossNamespaceImport *bool `json:"allowCrossNamespaceImport,omitempty"`
+
+	// selects the namespaces of the Grafanas for import, instances in namespaces not matching the selector are ignored.
+	// Enables cross namespace imports, unless allowCrossNamespaceImport is set to false.
+	// +optional
+	InstanceNamespaceSelector *metav1.LabelSelector `json:"instanceNamespaceSelector,omitempty"`
+}
+
+// GrafanaLibraryPanelStatus defines the observed state of GrafanaLibraryPanel
+type GrafanaLibraryPanelStatus struct {
+	ContentCache []byte `json:"contentCache,omitempty"`
+	// config maps holding a content cache that is too large for the status
+	ContentCacheRef  *ContentCacheReference `json:"contentCacheRef,omitempty"`
+	ContentTimestamp metav1.Time            `json:"contentTimestamp,omitempty"`
+	ContentUrl       string                 `json:"contentUrl,omitempty"`
+	// ETag returned with the content cache, sent to revalidate the cache once it expired
+	ContentEtag string `json:"contentEtag,omitempty"`
+	// Last-Modified header returned with the content cache, sent to revalidate the cache once it expired
+	ContentLastModified string `json:"contentLastModified,omitempty"`
+	Hash                string `json:"hash,omitempty"`
+	// The library panel instanceSelector can't find matching grafana instances
+	NoMatchingInstances bool `json:"NoMatchingInstances,omitempty"`
+	// sync status of the resource in each matching instance
+	// +optional
+	Instances InstanceSyncStatusList `json:"instances,omitempty"`
+	// generation of the resource the conditions were computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Ready, Synced and Stalled conditions of the resource
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// GrafanaLibraryPanel is the Schema for the grafanalibrarypanels API
+type GrafanaLibraryPanel struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GrafanaLibraryPanelSpec   `json:"spec,omitempty"`
+	Status GrafanaLibraryPanelStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// GrafanaLibraryPanelList contains a list of GrafanaLibraryPanel
+type GrafanaLibraryPanelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GrafanaLibraryPanel `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GrafanaLibraryPanel{}, &GrafanaLibraryPanelList{})
+}
+
+func (in *GrafanaLibraryPanelList) Find(namespace string, name string) *GrafanaLibraryPanel {
+	for _, panel := range in.Items {
+		if panel.Namespace == namespace && panel.Name == name {
+			return &panel
+		}
+	}
+	return nil
+}
+
+func (in *GrafanaLibraryPanel) Hash() string {
+	return in.HashContent([]byte(in.Spec.Json))
+}
+
+// HashContent hashes the panel json fetched from any of the sources, together with the fields of the spec that
+// require the panel to be imported again
+func (in *GrafanaLibraryPanel) HashContent(content []byte) string {
+	hash := sha256.New()
+	hash.Write(content)
+	if in.Spec.Uid != "" {
+		hash.Write([]byte(in.Spec.Uid))
+	}
+	// the panel has to be moved when the referenced folder changes
+	if in.Spec.FolderRef != nil {
+		hash.Write([]byte(fmt.Sprintf("%v/%v", in.GetFolderRefNamespace(), in.Spec.FolderRef.Name)))
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// Unchanged reports if the panel content hash matches the hash of the last import into the instance
+func (in *GrafanaLibraryPanel) Unchanged(grafana *Grafana, hash string) bool {
+	return hash == in.Status.Instances.GetHash(grafana)
+}
+
+// GetUid returns the uid of the library panel in the instances: spec.uid, the uid in the panel json or the uid of the CR
+func (in *GrafanaLibraryPanel) GetUid(jsonUid string) string {
+	if in.Spec.Uid != "" {
+		return in.Spec.Uid
+	}
+	if jsonUid != "" {
+		return jsonUid
+	}
+	return string(in.UID)
+}
+
+// GetFolderRefNamespace returns the namespace of the referenced folder, which defaults to the namespace of the panel
+func (in *GrafanaLibraryPanel) GetFolderRefNamespace() string {
+	if in.Spec.FolderRef == nil || in.Spec.FolderRef.Namespace == "" {
+		return in.Namespace
+	}
+	return in.Spec.FolderRef.Namespace
+}
+
+func (in *GrafanaLibraryPanel) GetResyncPeriod() time.Duration {
+	duration, err := time.ParseDuration(in.Spec.ResyncPeriod)
+	if err != nil {
+		duration, _ = time.ParseDuration(DefaultResyncPeriod)
+	}
+	return duration
+}
+
+func (in *GrafanaLibraryPanel) GetDeletionPolicy() DeletionPolicy {
+	return getDeletionPolicy(in.Spec.DeletionPolicy)
+}
+
+// GetSourceDashboard returns a dashboard with the sources and the content cache of the library panel, it is used to
+// fetch the panel json with the dashboard fetchers
+func (in *GrafanaLibraryPanel) GetSourceDashboard() *GrafanaDashboard {
+	return &GrafanaDashboard{
+		ObjectMeta: in.ObjectMeta,
+		Spec: GrafanaDashboardSpec{
+			Json:                 in.Spec.Json,
+			GzipJson:             in.Spec.GzipJson,
+			Url:                  in.Spec.Url,
+			Jsonnet:              in.Spec.Jsonnet,
+			InstanceSelector:     in.Spec.InstanceSelector,
+			ContentCacheDuration: in.Spec.ContentCacheDuration,
+		},
+		Status: GrafanaDashboardStatus{
+			ContentCache:        in.Status.ContentCache,
+			ContentCacheRef:     in.Status.ContentCacheRef,
+			ContentTimestamp:    in.Status.ContentTimestamp,
+			ContentUrl:          in.Status.ContentUrl,
+			ContentEtag:         in.Status.ContentEtag,
+			ContentLastModified: in.Status.ContentLastModified,
+		},
+	}
+}
+
+// SetContentCache copies the content cache of the source dashboard back into the status
+func (in *GrafanaLibraryPanel) SetContentCache(source *GrafanaDashboard) {
+	in.Status.ContentCache = source.Status.ContentCache
+	in.Status.ContentCacheRef = source.Status.ContentCacheRef
+	in.Status.ContentTimestamp = source.Status.ContentTimestamp
+	in.Status.ContentUrl = source.Status.ContentUrl
+	in.Status.ContentEtag = source.Status.ContentEtag
+	in.Status.ContentLastModified = source.Status.ContentLastModified
+}
+
+func (in *GrafanaLibraryPanel) IsAllowCrossNamespaceImport() bool {
+	return isAllowCrossNamespaceImport(in.Spec.AllowCrossNamespaceImport, in.Spec.InstanceNamespaceSelector)
+}
@@ -0,0 +1,33 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGrafanaLibraryPanel_SourceDashboard(t *testing.T) {
+	panel := &GrafanaLibraryPanel{
+		ObjectMeta: metav1.ObjectMeta{Name: "panel", Namespace: "grafana", UID: "cr-uid"},
+		Spec: GrafanaLibraryPanelSpec{
+			Url:                  "https://example.com/panel.json",
+			ContentCacheDuration: metav1.Duration{Duration: 60},
+		},
+		Status: GrafanaLibraryPanelStatus{
+			ContentUrl: "https://example.com/panel.json",
+		},
+	}
+
+	source := panel.GetSourceDashboard()
+	assert.Equal(t, "panel", source.Name)
+	assert.Equal(t, []DashboardSourceType{DashboardSourceTypeUrl}, source.GetSourceTypes())
+	assert.Equal(t, panel.Status.ContentUrl, source.Status.ContentUrl)
+
+	source.Status.ContentEtag = "etag"
+	panel.SetContentCache(source)
+	assert.Equal(t, "etag", panel.Status.ContentEtag)
+
+	assert.Equal(t, "cr-uid", panel.GetUid(""))
+	assert.Equal(t, "json-uid", panel.GetUid("json-uid"))
+}
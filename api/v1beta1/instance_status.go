@@ -0,0 +1,59 @@
+package v1beta1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InstanceSyncStatus is the sync status of a resource in one of the matching Grafana instances
+type InstanceSyncStatus struct {
+	// namespace/name of the Grafana instance
+	Instance string `json:"instance"`
+	// uid of the resource in the instance
+	// +optional
+	Uid string `json:"uid,omitempty"`
+	// time the content of the resource was last applied to the instance, it's kept while the content is unchanged
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// error of the last sync, empty if it succeeded
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// hash of the content applied in the instance, differs from the hash of the resource while the instance is out
+	// of date
+	// +optional
+	Hash string `json:"hash,omitempty"`
+	// true if the permissions of the resource were applied in the instance, they are reset once they are removed
+	// from the spec
+	// +optional
+	Permissions bool `json:"permissions,omitempty"`
+}
+
+type InstanceSyncStatusList []InstanceSyncStatus
+
+// Find returns the sync status of an instance
+func (in InstanceSyncStatusList) Find(instance string) *InstanceSyncStatus {
+	for _, status := range in {
+		if status.Instance == instance {
+			status := status
+			return &status
+		}
+	}
+	return nil
+}
+
+// GetHash returns the hash of the content applied in an instance, empty if the resource wasn't synced to it yet
+func (in InstanceSyncStatusList) GetHash(grafana *Grafana) string {
+	if status := in.Find(fmt.Sprintf("%v/%v", grafana.Namespace, grafana.Name)); status != nil {
+		return status.Hash
+	}
+	return ""
+}
+
+// HasPermissions returns true if the permissions of the resource were applied in an instance
+func (in InstanceSyncStatusList) HasPermissions(grafana *Grafana) bool {
+	if status := in.Find(fmt.Sprintf("%v/%v", grafana.Namespace, grafana.Name)); status != nil {
+		return status.Permissions
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package v1beta1
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// TruncateName returns the name of a generated resource. Names that exceed the maximum length of a resource name are
+// truncated and end with a hash of the full name, so that they stay unique.
+func TruncateName(name string) string {
+	if len(name) <= validation.DNS1123SubdomainMaxLength {
+		return name
+	}
+
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:16]
+	prefix := strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength-len(hash)-1], "-.")
+	return prefix + "-" + hash
+}
@@ -0,0 +1,32 @@
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestTruncateName(t *testing.T) {
+	assert.Equal(t, "loki-team-a", TruncateName("loki-team-a"))
+
+	prefix := strings.Repeat("a", 200) + "-" + strings.Repeat("b", 60)
+	long := TruncateName(prefix + "-1")
+	assert.Len(t, long, validation.DNS1123SubdomainMaxLength)
+	assert.Empty(t, validation.IsDNS1123Subdomain(long))
+	assert.NotEqual(t, long, TruncateName(prefix+"-2"))
+	assert.Equal(t, long, TruncateName(prefix+"-1"))
+}
+
+func TestGrafanaDatasourceTemplate_GetDatasourceName(t *testing.T) {
+	datasourceTemplate := &GrafanaDatasourceTemplate{ObjectMeta: metav1.ObjectMeta{Name: "loki"}}
+	namespace := &metav1.ObjectMeta{Name: "team-a"}
+	object := &metav1.ObjectMeta{Name: "tenant-1", Namespace: "team-a"}
+	assert.Equal(t, "loki-team-a", datasourceTemplate.GetDatasourceName(namespace))
+	assert.Equal(t, "loki-team-a-tenant-1", datasourceTemplate.GetDatasourceName(object))
+
+	datasourceTemplate.Name = strings.Repeat("a", 250)
+	assert.Len(t, datasourceTemplate.GetDatasourceName(namespace), validation.DNS1123SubdomainMaxLength)
+}
@@ -39,6 +39,24 @@ func (in NamespacedResourceList) Find(namespace string, name string) (bool, *str
 	return false, nil
 }
 
+// GetUid returns the uid of a resource in the list, or an empty string if the resource isn't in the list
+func (in NamespacedResourceList) GetUid(namespace string, name string) string {
+	if found, uid := in.Find(namespace, name); found {
+		return *uid
+	}
+	return ""
+}
+
+// ContainsUid returns true if any resource in the list has the given uid
+func (in NamespacedResourceList) ContainsUid(uid string) bool {
+	for _, r := range in {
+		if r.Uid() == uid {
+			return true
+		}
+	}
+	return false
+}
+
 func (in NamespacedResourceList) ForNamespace(namespace string) NamespacedResourceList {
 	resources := NamespacedResourceList{}
 	for _, r := range in {
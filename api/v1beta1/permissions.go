@@ -0,0 +1,40 @@
+package v1beta1
+
+// +kubebuilder:validation:Enum=View;Edit;Admin
+type PermissionLevel string
+
+const (
+	PermissionLevelView  PermissionLevel = "View"
+	PermissionLevelEdit  PermissionLevel = "Edit"
+	PermissionLevelAdmin PermissionLevel = "Admin"
+)
+
+// Permission grants a role, a team or a user access to a dashboard or folder, exactly one of them must be set
+type Permission struct {
+	// basic role of the organization
+	// +kubebuilder:validation:Enum=Viewer;Editor
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	// name of the team
+	// +optional
+	Team string `json:"team,omitempty"`
+
+	// login or email of the user
+	// +optional
+	User string `json:"user,omitempty"`
+
+	Permission PermissionLevel `json:"permission"`
+}
+
+// GetLevel returns the numeric permission level used by the Grafana api
+func (in Permission) GetLevel() int64 {
+	switch in.Permission {
+	case PermissionLevelEdit:
+		return 2
+	case PermissionLevelAdmin:
+		return 4
+	default:
+		return 1
+	}
+}
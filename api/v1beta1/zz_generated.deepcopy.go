@@ -31,6 +31,51 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CaBundleSource) DeepCopyInto(out *CaBundleSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaBundleSource.
+func (in *CaBundleSource) DeepCopy() *CaBundleSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CaBundleSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContentCacheReference) DeepCopyInto(out *ContentCacheReference) {
+	*out = *in
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContentCacheReference.
+func (in *ContentCacheReference) DeepCopy() *ContentCacheReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ContentCacheReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentV1) DeepCopyInto(out *DeploymentV1) {
 	*out = *in
@@ -355,73 +400,46 @@ func (in *GrafanaClient) DeepCopy() *GrafanaClient {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDashboard) DeepCopyInto(out *GrafanaDashboard) {
+func (in *GrafanaComDashboardReference) DeepCopyInto(out *GrafanaComDashboardReference) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboard.
-func (in *GrafanaDashboard) DeepCopy() *GrafanaDashboard {
-	if in == nil {
-		return nil
-	}
-	out := new(GrafanaDashboard)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaDashboard) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+	if in.Revision != nil {
+		in, out := &in.Revision, &out.Revision
+		*out = new(int)
+		**out = **in
 	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDashboardDatasource) DeepCopyInto(out *GrafanaDashboardDatasource) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardDatasource.
-func (in *GrafanaDashboardDatasource) DeepCopy() *GrafanaDashboardDatasource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaComDashboardReference.
+func (in *GrafanaComDashboardReference) DeepCopy() *GrafanaComDashboardReference {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDashboardDatasource)
+	out := new(GrafanaComDashboardReference)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDashboardList) DeepCopyInto(out *GrafanaDashboardList) {
+func (in *GrafanaDashboard) DeepCopyInto(out *GrafanaDashboard) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]GrafanaDashboard, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardList.
-func (in *GrafanaDashboardList) DeepCopy() *GrafanaDashboardList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboard.
+func (in *GrafanaDashboard) DeepCopy() *GrafanaDashboard {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDashboardList)
+	out := new(GrafanaDashboard)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaDashboardList) DeepCopyObject() runtime.Object {
+func (in *GrafanaDashboard) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -429,88 +447,67 @@ func (in *GrafanaDashboardList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDashboardSpec) DeepCopyInto(out *GrafanaDashboardSpec) {
+func (in *GrafanaDashboardDatasource) DeepCopyInto(out *GrafanaDashboardDatasource) {
 	*out = *in
-	if in.GzipJson != nil {
-		in, out := &in.GzipJson, &out.GzipJson
-		*out = make([]byte, len(*in))
-		copy(*out, *in)
-	}
-	if in.InstanceSelector != nil {
-		in, out := &in.InstanceSelector, &out.InstanceSelector
-		*out = new(metav1.LabelSelector)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Plugins != nil {
-		in, out := &in.Plugins, &out.Plugins
-		*out = make(PluginList, len(*in))
-		copy(*out, *in)
-	}
-	out.ContentCacheDuration = in.ContentCacheDuration
-	if in.Datasources != nil {
-		in, out := &in.Datasources, &out.Datasources
-		*out = make([]GrafanaDashboardDatasource, len(*in))
-		copy(*out, *in)
-	}
-	if in.AllowCrossNamespaceImport != nil {
-		in, out := &in.AllowCrossNamespaceImport, &out.AllowCrossNamespaceImport
-		*out = new(bool)
+	if in.DatasourceRef != nil {
+		in, out := &in.DatasourceRef, &out.DatasourceRef
+		*out = new(GrafanaDatasourceReference)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSpec.
-func (in *GrafanaDashboardSpec) DeepCopy() *GrafanaDashboardSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardDatasource.
+func (in *GrafanaDashboardDatasource) DeepCopy() *GrafanaDashboardDatasource {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDashboardSpec)
+	out := new(GrafanaDashboardDatasource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDashboardStatus) DeepCopyInto(out *GrafanaDashboardStatus) {
+func (in *GrafanaDashboardDrift) DeepCopyInto(out *GrafanaDashboardDrift) {
 	*out = *in
-	if in.ContentCache != nil {
-		in, out := &in.ContentCache, &out.ContentCache
-		*out = make([]byte, len(*in))
+	if in.ChangedFields != nil {
+		in, out := &in.ChangedFields, &out.ChangedFields
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	in.ContentTimestamp.DeepCopyInto(&out.ContentTimestamp)
+	in.DetectedAt.DeepCopyInto(&out.DetectedAt)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardStatus.
-func (in *GrafanaDashboardStatus) DeepCopy() *GrafanaDashboardStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardDrift.
+func (in *GrafanaDashboardDrift) DeepCopy() *GrafanaDashboardDrift {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDashboardStatus)
+	out := new(GrafanaDashboardDrift)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDatasource) DeepCopyInto(out *GrafanaDatasource) {
+func (in *GrafanaDashboardExport) DeepCopyInto(out *GrafanaDashboardExport) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasource.
-func (in *GrafanaDatasource) DeepCopy() *GrafanaDatasource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardExport.
+func (in *GrafanaDashboardExport) DeepCopy() *GrafanaDashboardExport {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDatasource)
+	out := new(GrafanaDashboardExport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaDatasource) DeepCopyObject() runtime.Object {
+func (in *GrafanaDashboardExport) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -518,76 +515,31 @@ func (in *GrafanaDatasource) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDatasourceInternal) DeepCopyInto(out *GrafanaDatasourceInternal) {
-	*out = *in
-	if in.OrgID != nil {
-		in, out := &in.OrgID, &out.OrgID
-		*out = new(int64)
-		**out = **in
-	}
-	if in.IsDefault != nil {
-		in, out := &in.IsDefault, &out.IsDefault
-		*out = new(bool)
-		**out = **in
-	}
-	if in.BasicAuth != nil {
-		in, out := &in.BasicAuth, &out.BasicAuth
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Editable != nil {
-		in, out := &in.Editable, &out.Editable
-		*out = new(bool)
-		**out = **in
-	}
-	if in.JSONData != nil {
-		in, out := &in.JSONData, &out.JSONData
-		*out = make(json.RawMessage, len(*in))
-		copy(*out, *in)
-	}
-	if in.SecureJSONData != nil {
-		in, out := &in.SecureJSONData, &out.SecureJSONData
-		*out = make(json.RawMessage, len(*in))
-		copy(*out, *in)
-	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceInternal.
-func (in *GrafanaDatasourceInternal) DeepCopy() *GrafanaDatasourceInternal {
-	if in == nil {
-		return nil
-	}
-	out := new(GrafanaDatasourceInternal)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDatasourceList) DeepCopyInto(out *GrafanaDatasourceList) {
+func (in *GrafanaDashboardExportList) DeepCopyInto(out *GrafanaDashboardExportList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]GrafanaDatasource, len(*in))
+		*out = make([]GrafanaDashboardExport, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceList.
-func (in *GrafanaDatasourceList) DeepCopy() *GrafanaDatasourceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardExportList.
+func (in *GrafanaDashboardExportList) DeepCopy() *GrafanaDashboardExportList {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDatasourceList)
+	out := new(GrafanaDashboardExportList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaDatasourceList) DeepCopyObject() runtime.Object {
+func (in *GrafanaDashboardExportList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -595,113 +547,94 @@ func (in *GrafanaDatasourceList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDatasourceSpec) DeepCopyInto(out *GrafanaDatasourceSpec) {
+func (in *GrafanaDashboardExportSpec) DeepCopyInto(out *GrafanaDashboardExportSpec) {
 	*out = *in
-	if in.Datasource != nil {
-		in, out := &in.Datasource, &out.Datasource
-		*out = new(GrafanaDatasourceInternal)
-		(*in).DeepCopyInto(*out)
-	}
 	if in.InstanceSelector != nil {
 		in, out := &in.InstanceSelector, &out.InstanceSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Plugins != nil {
-		in, out := &in.Plugins, &out.Plugins
-		*out = make(PluginList, len(*in))
-		copy(*out, *in)
-	}
-	if in.Secrets != nil {
-		in, out := &in.Secrets, &out.Secrets
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 	if in.AllowCrossNamespaceImport != nil {
 		in, out := &in.AllowCrossNamespaceImport, &out.AllowCrossNamespaceImport
 		*out = new(bool)
 		**out = **in
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceSpec.
-func (in *GrafanaDatasourceSpec) DeepCopy() *GrafanaDatasourceSpec {
-	if in == nil {
-		return nil
+	if in.InstanceNamespaceSelector != nil {
+		in, out := &in.InstanceNamespaceSelector, &out.InstanceNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DashboardInstanceSelector != nil {
+		in, out := &in.DashboardInstanceSelector, &out.DashboardInstanceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
 	}
-	out := new(GrafanaDatasourceSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaDatasourceStatus) DeepCopyInto(out *GrafanaDatasourceStatus) {
-	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceStatus.
-func (in *GrafanaDatasourceStatus) DeepCopy() *GrafanaDatasourceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardExportSpec.
+func (in *GrafanaDashboardExportSpec) DeepCopy() *GrafanaDashboardExportSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaDatasourceStatus)
+	out := new(GrafanaDashboardExportSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaFolder) DeepCopyInto(out *GrafanaFolder) {
+func (in *GrafanaDashboardExportStatus) DeepCopyInto(out *GrafanaDashboardExportStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastExportTime.DeepCopyInto(&out.LastExportTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaFolder.
-func (in *GrafanaFolder) DeepCopy() *GrafanaFolder {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardExportStatus.
+func (in *GrafanaDashboardExportStatus) DeepCopy() *GrafanaDashboardExportStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaFolder)
+	out := new(GrafanaDashboardExportStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaFolder) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaFolderList) DeepCopyInto(out *GrafanaFolderList) {
+func (in *GrafanaDashboardList) DeepCopyInto(out *GrafanaDashboardList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]GrafanaFolder, len(*in))
+		*out = make([]GrafanaDashboard, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaFolderList.
-func (in *GrafanaFolderList) DeepCopy() *GrafanaFolderList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardList.
+func (in *GrafanaDashboardList) DeepCopy() *GrafanaDashboardList {
 	if in == nil {
 		return nil
 	}
-	out := new(GrafanaFolderList)
+	out := new(GrafanaDashboardList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GrafanaFolderList) DeepCopyObject() runtime.Object {
+func (in *GrafanaDashboardList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -709,9 +642,725 @@ func (in *GrafanaFolderList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GrafanaFolderSpec) DeepCopyInto(out *GrafanaFolderSpec) {
+func (in *GrafanaDashboardSpec) DeepCopyInto(out *GrafanaDashboardSpec) {
 	*out = *in
-	if in.InstanceSelector != nil {
+	if in.GzipJson != nil {
+		in, out := &in.GzipJson, &out.GzipJson
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.UrlAuthorization != nil {
+		in, out := &in.UrlAuthorization, &out.UrlAuthorization
+		*out = new(GrafanaDashboardUrlAuthorization)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UrlCaBundle != nil {
+		in, out := &in.UrlCaBundle, &out.UrlCaBundle
+		*out = new(CaBundleSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JsonnetExtVars != nil {
+		in, out := &in.JsonnetExtVars, &out.JsonnetExtVars
+		*out = make([]JsonnetVariable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JsonnetTLAs != nil {
+		in, out := &in.JsonnetTLAs, &out.JsonnetTLAs
+		*out = make([]JsonnetVariable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.JsonnetProject != nil {
+		in, out := &in.JsonnetProject, &out.JsonnetProject
+		*out = new(JsonnetProject)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GrafanaCom != nil {
+		in, out := &in.GrafanaCom, &out.GrafanaCom
+		*out = new(GrafanaComDashboardReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceSelector != nil {
+		in, out := &in.InstanceSelector, &out.InstanceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FolderRef != nil {
+		in, out := &in.FolderRef, &out.FolderRef
+		*out = new(GrafanaFolderReference)
+		**out = **in
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make(PluginList, len(*in))
+		copy(*out, *in)
+	}
+	out.ContentCacheDuration = in.ContentCacheDuration
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]Permission, len(*in))
+		copy(*out, *in)
+	}
+	if in.Datasources != nil {
+		in, out := &in.Datasources, &out.Datasources
+		*out = make([]GrafanaDashboardDatasource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowCrossNamespaceImport != nil {
+		in, out := &in.AllowCrossNamespaceImport, &out.AllowCrossNamespaceImport
+		*out = new(bool)
+		**out = **in
+	}
+	if in.InstanceNamespaceSelector != nil {
+		in, out := &in.InstanceNamespaceSelector, &out.InstanceNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardSpec.
+func (in *GrafanaDashboardSpec) DeepCopy() *GrafanaDashboardSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardStatus) DeepCopyInto(out *GrafanaDashboardStatus) {
+	*out = *in
+	if in.ContentCache != nil {
+		in, out := &in.ContentCache, &out.ContentCache
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContentCacheRef != nil {
+		in, out := &in.ContentCacheRef, &out.ContentCacheRef
+		*out = new(ContentCacheReference)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ContentTimestamp.DeepCopyInto(&out.ContentTimestamp)
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]GrafanaDashboardDrift, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make(InstanceSyncStatusList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardStatus.
+func (in *GrafanaDashboardStatus) DeepCopy() *GrafanaDashboardStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardUrlAuthorization) DeepCopyInto(out *GrafanaDashboardUrlAuthorization) {
+	*out = *in
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(GrafanaDashboardUrlBasicAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BearerToken != nil {
+		in, out := &in.BearerToken, &out.BearerToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]GrafanaDashboardUrlHeader, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardUrlAuthorization.
+func (in *GrafanaDashboardUrlAuthorization) DeepCopy() *GrafanaDashboardUrlAuthorization {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardUrlAuthorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardUrlBasicAuth) DeepCopyInto(out *GrafanaDashboardUrlBasicAuth) {
+	*out = *in
+	if in.Username != nil {
+		in, out := &in.Username, &out.Username
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardUrlBasicAuth.
+func (in *GrafanaDashboardUrlBasicAuth) DeepCopy() *GrafanaDashboardUrlBasicAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardUrlBasicAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDashboardUrlHeader) DeepCopyInto(out *GrafanaDashboardUrlHeader) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDashboardUrlHeader.
+func (in *GrafanaDashboardUrlHeader) DeepCopy() *GrafanaDashboardUrlHeader {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDashboardUrlHeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasource) DeepCopyInto(out *GrafanaDatasource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasource.
+func (in *GrafanaDatasource) DeepCopy() *GrafanaDatasource {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaDatasource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceHealth) DeepCopyInto(out *GrafanaDatasourceHealth) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceHealth.
+func (in *GrafanaDatasourceHealth) DeepCopy() *GrafanaDatasourceHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceHealthCheck) DeepCopyInto(out *GrafanaDatasourceHealthCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceHealthCheck.
+func (in *GrafanaDatasourceHealthCheck) DeepCopy() *GrafanaDatasourceHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceInternal) DeepCopyInto(out *GrafanaDatasourceInternal) {
+	*out = *in
+	if in.OrgID != nil {
+		in, out := &in.OrgID, &out.OrgID
+		*out = new(int64)
+		**out = **in
+	}
+	if in.IsDefault != nil {
+		in, out := &in.IsDefault, &out.IsDefault
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Editable != nil {
+		in, out := &in.Editable, &out.Editable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.JSONData != nil {
+		in, out := &in.JSONData, &out.JSONData
+		*out = make(json.RawMessage, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecureJSONData != nil {
+		in, out := &in.SecureJSONData, &out.SecureJSONData
+		*out = make(json.RawMessage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceInternal.
+func (in *GrafanaDatasourceInternal) DeepCopy() *GrafanaDatasourceInternal {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceInternal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceList) DeepCopyInto(out *GrafanaDatasourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaDatasource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceList.
+func (in *GrafanaDatasourceList) DeepCopy() *GrafanaDatasourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaDatasourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceReference) DeepCopyInto(out *GrafanaDatasourceReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceReference.
+func (in *GrafanaDatasourceReference) DeepCopy() *GrafanaDatasourceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceSpec) DeepCopyInto(out *GrafanaDatasourceSpec) {
+	*out = *in
+	if in.Datasource != nil {
+		in, out := &in.Datasource, &out.Datasource
+		*out = new(GrafanaDatasourceInternal)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceSelector != nil {
+		in, out := &in.InstanceSelector, &out.InstanceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make(PluginList, len(*in))
+		copy(*out, *in)
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]GrafanaDatasourceValueFrom, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(GrafanaDatasourceHealthCheck)
+		**out = **in
+	}
+	if in.AllowCrossNamespaceImport != nil {
+		in, out := &in.AllowCrossNamespaceImport, &out.AllowCrossNamespaceImport
+		*out = new(bool)
+		**out = **in
+	}
+	if in.InstanceNamespaceSelector != nil {
+		in, out := &in.InstanceNamespaceSelector, &out.InstanceNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceSpec.
+func (in *GrafanaDatasourceSpec) DeepCopy() *GrafanaDatasourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceStatus) DeepCopyInto(out *GrafanaDatasourceStatus) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make(InstanceSyncStatusList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = make([]GrafanaDatasourceHealth, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceStatus.
+func (in *GrafanaDatasourceStatus) DeepCopy() *GrafanaDatasourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceTemplate) DeepCopyInto(out *GrafanaDatasourceTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceTemplate.
+func (in *GrafanaDatasourceTemplate) DeepCopy() *GrafanaDatasourceTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaDatasourceTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceTemplateList) DeepCopyInto(out *GrafanaDatasourceTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaDatasourceTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceTemplateList.
+func (in *GrafanaDatasourceTemplateList) DeepCopy() *GrafanaDatasourceTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaDatasourceTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceTemplateObjects) DeepCopyInto(out *GrafanaDatasourceTemplateObjects) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceTemplateObjects.
+func (in *GrafanaDatasourceTemplateObjects) DeepCopy() *GrafanaDatasourceTemplateObjects {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceTemplateObjects)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceTemplateSpec) DeepCopyInto(out *GrafanaDatasourceTemplateSpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = new(GrafanaDatasourceTemplateObjects)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceTemplateSpec.
+func (in *GrafanaDatasourceTemplateSpec) DeepCopy() *GrafanaDatasourceTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceTemplateStatus) DeepCopyInto(out *GrafanaDatasourceTemplateStatus) {
+	*out = *in
+	if in.Datasources != nil {
+		in, out := &in.Datasources, &out.Datasources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceTemplateStatus.
+func (in *GrafanaDatasourceTemplateStatus) DeepCopy() *GrafanaDatasourceTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceValueFrom) DeepCopyInto(out *GrafanaDatasourceValueFrom) {
+	*out = *in
+	in.ValueFrom.DeepCopyInto(&out.ValueFrom)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceValueFrom.
+func (in *GrafanaDatasourceValueFrom) DeepCopy() *GrafanaDatasourceValueFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceValueFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaDatasourceValueFromSource) DeepCopyInto(out *GrafanaDatasourceValueFromSource) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaDatasourceValueFromSource.
+func (in *GrafanaDatasourceValueFromSource) DeepCopy() *GrafanaDatasourceValueFromSource {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaDatasourceValueFromSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaFolder) DeepCopyInto(out *GrafanaFolder) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaFolder.
+func (in *GrafanaFolder) DeepCopy() *GrafanaFolder {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaFolder)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaFolder) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaFolderList) DeepCopyInto(out *GrafanaFolderList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaFolder, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaFolderList.
+func (in *GrafanaFolderList) DeepCopy() *GrafanaFolderList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaFolderList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaFolderList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaFolderReference) DeepCopyInto(out *GrafanaFolderReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaFolderReference.
+func (in *GrafanaFolderReference) DeepCopy() *GrafanaFolderReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaFolderReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaFolderSpec) DeepCopyInto(out *GrafanaFolderSpec) {
+	*out = *in
+	if in.InstanceSelector != nil {
 		in, out := &in.InstanceSelector, &out.InstanceSelector
 		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
@@ -721,6 +1370,16 @@ func (in *GrafanaFolderSpec) DeepCopyInto(out *GrafanaFolderSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.InstanceNamespaceSelector != nil {
+		in, out := &in.InstanceNamespaceSelector, &out.InstanceNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]Permission, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaFolderSpec.
@@ -736,6 +1395,20 @@ func (in *GrafanaFolderSpec) DeepCopy() *GrafanaFolderSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaFolderStatus) DeepCopyInto(out *GrafanaFolderStatus) {
 	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make(InstanceSyncStatusList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaFolderStatus.
@@ -748,6 +1421,146 @@ func (in *GrafanaFolderStatus) DeepCopy() *GrafanaFolderStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaLibraryPanel) DeepCopyInto(out *GrafanaLibraryPanel) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaLibraryPanel.
+func (in *GrafanaLibraryPanel) DeepCopy() *GrafanaLibraryPanel {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaLibraryPanel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaLibraryPanel) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaLibraryPanelList) DeepCopyInto(out *GrafanaLibraryPanelList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GrafanaLibraryPanel, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaLibraryPanelList.
+func (in *GrafanaLibraryPanelList) DeepCopy() *GrafanaLibraryPanelList {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaLibraryPanelList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GrafanaLibraryPanelList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaLibraryPanelSpec) DeepCopyInto(out *GrafanaLibraryPanelSpec) {
+	*out = *in
+	if in.GzipJson != nil {
+		in, out := &in.GzipJson, &out.GzipJson
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.InstanceSelector != nil {
+		in, out := &in.InstanceSelector, &out.InstanceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FolderRef != nil {
+		in, out := &in.FolderRef, &out.FolderRef
+		*out = new(GrafanaFolderReference)
+		**out = **in
+	}
+	out.ContentCacheDuration = in.ContentCacheDuration
+	if in.AllowCrossNamespaceImport != nil {
+		in, out := &in.AllowCrossNamespaceImport, &out.AllowCrossNamespaceImport
+		*out = new(bool)
+		**out = **in
+	}
+	if in.InstanceNamespaceSelector != nil {
+		in, out := &in.InstanceNamespaceSelector, &out.InstanceNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaLibraryPanelSpec.
+func (in *GrafanaLibraryPanelSpec) DeepCopy() *GrafanaLibraryPanelSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaLibraryPanelSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GrafanaLibraryPanelStatus) DeepCopyInto(out *GrafanaLibraryPanelStatus) {
+	*out = *in
+	if in.ContentCache != nil {
+		in, out := &in.ContentCache, &out.ContentCache
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContentCacheRef != nil {
+		in, out := &in.ContentCacheRef, &out.ContentCacheRef
+		*out = new(ContentCacheReference)
+		(*in).DeepCopyInto(*out)
+	}
+	in.ContentTimestamp.DeepCopyInto(&out.ContentTimestamp)
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make(InstanceSyncStatusList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaLibraryPanelStatus.
+func (in *GrafanaLibraryPanelStatus) DeepCopy() *GrafanaLibraryPanelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GrafanaLibraryPanelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrafanaList) DeepCopyInto(out *GrafanaList) {
 	*out = *in
@@ -890,6 +1703,18 @@ func (in *GrafanaStatus) DeepCopyInto(out *GrafanaStatus) {
 		*out = make(NamespacedResourceList, len(*in))
 		copy(*out, *in)
 	}
+	if in.LibraryPanels != nil {
+		in, out := &in.LibraryPanels, &out.LibraryPanels
+		*out = make(NamespacedResourceList, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GrafanaStatus.
@@ -923,6 +1748,46 @@ func (in *IngressNetworkingV1) DeepCopy() *IngressNetworkingV1 {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceSyncStatus) DeepCopyInto(out *InstanceSyncStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSyncStatus.
+func (in *InstanceSyncStatus) DeepCopy() *InstanceSyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceSyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in InstanceSyncStatusList) DeepCopyInto(out *InstanceSyncStatusList) {
+	{
+		in := &in
+		*out = make(InstanceSyncStatusList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSyncStatusList.
+func (in InstanceSyncStatusList) DeepCopy() InstanceSyncStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceSyncStatusList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JsonnetConfig) DeepCopyInto(out *JsonnetConfig) {
 	*out = *in
@@ -943,6 +1808,76 @@ func (in *JsonnetConfig) DeepCopy() *JsonnetConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonnetProject) DeepCopyInto(out *JsonnetProject) {
+	*out = *in
+	if in.GzipTar != nil {
+		in, out := &in.GzipTar, &out.GzipTar
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonnetProject.
+func (in *JsonnetProject) DeepCopy() *JsonnetProject {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonnetProject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonnetVariable) DeepCopyInto(out *JsonnetVariable) {
+	*out = *in
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = new(JsonnetVariableSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonnetVariable.
+func (in *JsonnetVariable) DeepCopy() *JsonnetVariable {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonnetVariable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonnetVariableSource) DeepCopyInto(out *JsonnetVariableSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonnetVariableSource.
+func (in *JsonnetVariableSource) DeepCopy() *JsonnetVariableSource {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonnetVariableSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in NamespacedResourceList) DeepCopyInto(out *NamespacedResourceList) {
 	{
@@ -1006,6 +1941,21 @@ func (in *OperatorReconcileVars) DeepCopy() *OperatorReconcileVars {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Permission) DeepCopyInto(out *Permission) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Permission.
+func (in *Permission) DeepCopy() *Permission {
+	if in == nil {
+		return nil
+	}
+	out := new(Permission)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistentVolumeClaimV1) DeepCopyInto(out *PersistentVolumeClaimV1) {
 	*out = *in
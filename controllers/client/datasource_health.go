@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DatasourceHealth is the result of a datasource health check as returned by the instance
+type DatasourceHealth struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// CheckDatasourceHealth calls the health endpoint of a datasource, the api client doesn't support it. A failed health
+// check is returned as result, an error means the health check couldn't be run.
+func CheckDatasourceHealth(ctx context.Context, c client.Client, grafana *v1beta1.Grafana, uid string) (*DatasourceHealth, error) {
+	credentials, err := getAdminCredentials(ctx, c, grafana)
+	if err != nil {
+		return nil, err
+	}
+
+	healthUrl := fmt.Sprintf("%v/api/datasources/uid/%v/health", strings.TrimSuffix(grafana.Status.AdminUrl, "/"), url.PathEscape(uid))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, healthUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if credentials.apikey != "" {
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %v", credentials.apikey))
+	} else if credentials.username != "" && credentials.password != "" {
+		request.SetBasicAuth(credentials.username, credentials.password)
+	}
+
+	httpClient := &http.Client{
+		Transport: NewInstrumentedRoundTripper(grafana.Name, metrics.GrafanaApiRequests),
+		Timeout:   getClientTimeout(grafana),
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// failed health checks are reported with a client or server error and a result in the body
+	health := &DatasourceHealth{}
+	if err := json.Unmarshal(body, health); err != nil || health.Status == "" {
+		return nil, fmt.Errorf("status: %d, body: %v", response.StatusCode, string(body))
+	}
+
+	if response.StatusCode != http.StatusOK && health.Status == v1beta1.DatasourceHealthOK {
+		health.Status = v1beta1.DatasourceHealthError
+	}
+	return health, nil
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckDatasourceHealth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		switch r.URL.Path {
+		case "/api/datasources/uid/healthy/health":
+			_, _ = w.Write([]byte(`{"status":"OK","message":"Data source is working"}`))
+		case "/api/datasources/uid/unhealthy/health":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"ERROR","message":"connection refused"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Plugin health check not implemented"}`))
+		}
+	}))
+	defer server.Close()
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("token")},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret).Build()
+
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "default"},
+		Spec: v1beta1.GrafanaSpec{
+			External: &v1beta1.External{
+				URL:    server.URL,
+				ApiKey: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "credentials"}, Key: "token"},
+			},
+		},
+		Status: v1beta1.GrafanaStatus{AdminUrl: server.URL},
+	}
+
+	health, err := CheckDatasourceHealth(context.Background(), c, grafana, "healthy")
+	assert.NoError(t, err)
+	assert.Equal(t, &DatasourceHealth{Status: v1beta1.DatasourceHealthOK, Message: "Data source is working"}, health)
+
+	health, err = CheckDatasourceHealth(context.Background(), c, grafana, "unhealthy")
+	assert.NoError(t, err)
+	assert.Equal(t, &DatasourceHealth{Status: v1beta1.DatasourceHealthError, Message: "connection refused"}, health)
+
+	_, err = CheckDatasourceHealth(context.Background(), c, grafana, "unsupported")
+	assert.ErrorContains(t, err, "status: 404")
+}
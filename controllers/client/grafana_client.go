@@ -124,7 +124,8 @@ func getAdminCredentials(ctx context.Context, c client.Client, grafana *v1beta1.
 	return credentials, nil
 }
 
-func NewGrafanaClient(ctx context.Context, c client.Client, grafana *v1beta1.Grafana) (*grapi.Client, error) {
+// getClientTimeout returns the timeout of requests against the instance
+func getClientTimeout(grafana *v1beta1.Grafana) time.Duration {
 	var timeout time.Duration
 	if grafana.Spec.Client != nil && grafana.Spec.Client.TimeoutSeconds != nil {
 		timeout = time.Duration(*grafana.Spec.Client.TimeoutSeconds)
@@ -134,7 +135,10 @@ func NewGrafanaClient(ctx context.Context, c client.Client, grafana *v1beta1.Gra
 	} else {
 		timeout = 10
 	}
+	return time.Second * timeout
+}
 
+func NewGrafanaClient(ctx context.Context, c client.Client, grafana *v1beta1.Grafana) (*grapi.Client, error) {
 	credentials, err := getAdminCredentials(ctx, c, grafana)
 	if err != nil {
 		return nil, err
@@ -144,7 +148,7 @@ func NewGrafanaClient(ctx context.Context, c client.Client, grafana *v1beta1.Gra
 		HTTPHeaders: nil,
 		Client: &http.Client{
 			Transport: NewInstrumentedRoundTripper(grafana.Name, metrics.GrafanaApiRequests),
-			Timeout:   time.Second * timeout,
+			Timeout:   getClientTimeout(grafana),
 		},
 		// TODO populate me
 		OrgID: 0,
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	grapi "github.com/grafana/grafana-api-golang-client"
+)
+
+// InventoryTTL is how long the dashboards and folders listed from an instance are reused before they are listed again
+var InventoryTTL = time.Minute
+
+// SearchPageSize is the number of results requested per page from the search api of an instance
+var SearchPageSize = 1000
+
+const (
+	searchTypeDashboard = "dash-db"
+	searchTypeFolder    = "dash-folder"
+)
+
+var (
+	inventories     = map[string]*Inventory{}
+	inventoriesLock sync.Mutex
+)
+
+// Inventory caches the dashboards and folders of an instance, it is shared by all controllers. Lookups by uid should
+// be sent to the instance directly, the inventory is meant for lookups Grafana doesn't support otherwise.
+type Inventory struct {
+	lock    sync.Mutex
+	results map[string]*inventoryResults
+}
+
+type inventoryResults struct {
+	items     []grapi.FolderDashboardSearchResponse
+	timestamp time.Time
+}
+
+// GetInventory returns the inventory of an instance
+func GetInventory(grafana *v1beta1.Grafana) *Inventory {
+	inventoriesLock.Lock()
+	defer inventoriesLock.Unlock()
+
+	key := getInventoryKey(grafana.Namespace, grafana.Name)
+	inventory, ok := inventories[key]
+	if !ok {
+		inventory = &Inventory{
+			results: map[string]*inventoryResults{},
+		}
+		inventories[key] = inventory
+	}
+	return inventory
+}
+
+// DeleteInventory drops the inventory of an instance, it has to be called once the instance was deleted
+func DeleteInventory(namespace string, name string) {
+	inventoriesLock.Lock()
+	defer inventoriesLock.Unlock()
+
+	delete(inventories, getInventoryKey(namespace, name))
+}
+
+func getInventoryKey(namespace string, name string) string {
+	return fmt.Sprintf("%v/%v", namespace, name)
+}
+
+// Dashboards returns all dashboards of the instance
+func (in *Inventory) Dashboards(client *grapi.Client) ([]grapi.FolderDashboardSearchResponse, error) {
+	return in.search(client, searchTypeDashboard)
+}
+
+// Folders returns all folders of the instance
+func (in *Inventory) Folders(client *grapi.Client) ([]grapi.FolderDashboardSearchResponse, error) {
+	return in.search(client, searchTypeFolder)
+}
+
+// Invalidate drops the cached results, it has to be called after dashboards or folders were created, moved or deleted
+func (in *Inventory) Invalidate() {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+	in.results = map[string]*inventoryResults{}
+}
+
+func (in *Inventory) search(client *grapi.Client, searchType string) ([]grapi.FolderDashboardSearchResponse, error) {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	if cached, ok := in.results[searchType]; ok && time.Since(cached.timestamp) < InventoryTTL {
+		return cached.items, nil
+	}
+
+	items, err := SearchAll(client, url.Values{"type": {searchType}})
+	if err != nil {
+		return nil, err
+	}
+
+	in.results[searchType] = &inventoryResults{
+		items:     items,
+		timestamp: time.Now(),
+	}
+	return items, nil
+}
+
+// SearchAll pages through the results of the search api, a single request returns at most the search limit of the
+// instance
+func SearchAll(client *grapi.Client, params url.Values) ([]grapi.FolderDashboardSearchResponse, error) {
+	var items []grapi.FolderDashboardSearchResponse
+	for page := 1; ; page++ {
+		pageParams := url.Values{}
+		for key, values := range params {
+			pageParams[key] = values
+		}
+		pageParams.Set("limit", strconv.Itoa(SearchPageSize))
+		pageParams.Set("page", strconv.Itoa(page))
+
+		results, err := client.FolderDashboardSearch(pageParams)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, results...)
+		if len(results) < SearchPageSize {
+			return items, nil
+		}
+	}
+}
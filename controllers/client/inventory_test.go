@@ -0,0 +1,68 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	grapi "github.com/grafana/grafana-api-golang-client"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInventory(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "dash-db", r.URL.Query().Get("type"))
+
+		// 5 dashboards in pages of 2
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var results []grapi.FolderDashboardSearchResponse
+		for i := (page - 1) * limit; i < page*limit && i < 5; i++ {
+			results = append(results, grapi.FolderDashboardSearchResponse{UID: strconv.Itoa(i)})
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	}))
+	defer server.Close()
+
+	pageSize := SearchPageSize
+	SearchPageSize = 2
+	defer func() { SearchPageSize = pageSize }()
+
+	client, err := grapi.New(server.URL, grapi.Config{})
+	assert.Nil(t, err)
+
+	grafana := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "inventory-test"}}
+	inventory := GetInventory(grafana)
+	assert.Same(t, inventory, GetInventory(grafana))
+
+	dashboards, err := inventory.Dashboards(client)
+	assert.Nil(t, err)
+	assert.Len(t, dashboards, 5)
+	assert.Equal(t, 3, requests)
+
+	// served from the cache until invalidated
+	_, err = inventory.Dashboards(client)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, requests)
+
+	inventory.Invalidate()
+	_, err = inventory.Dashboards(client)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, requests)
+}
+
+func TestDeleteInventory(t *testing.T) {
+	grafana := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "inventory-delete-test"}}
+	inventory := GetInventory(grafana)
+	assert.Contains(t, inventories, "inventory-delete-test/grafana")
+
+	DeleteInventory(grafana.Namespace, grafana.Name)
+	assert.NotContains(t, inventories, "inventory-delete-test/grafana")
+	assert.NotSame(t, inventory, GetInventory(grafana))
+}
@@ -4,10 +4,18 @@ import (
 	"crypto/tls"
 	"net/http"
 	"strconv"
+	"sync"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+const insecureTransportKey = "insecure"
+
+var (
+	transports     = map[string]*http.Transport{}
+	transportsLock sync.Mutex
+)
+
 type instrumentedRoundTripper struct {
 	relatedResource string
 	wrapped         http.RoundTripper
@@ -15,19 +23,37 @@ type instrumentedRoundTripper struct {
 }
 
 func NewInstrumentedRoundTripper(relatedResource string, metric *prometheus.CounterVec) http.RoundTripper {
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true, //nolint
-		},
-	}
+	return NewInstrumentedRoundTripperWithTLSConfig(relatedResource, metric, insecureTransportKey, &tls.Config{
+		InsecureSkipVerify: true, //nolint
+	})
+}
 
+// NewInstrumentedRoundTripperWithTLSConfig returns an instrumented round tripper that verifies servers according to
+// tlsConfig. Round trippers with the same key share a transport and its connections, so the key has to identify the
+// tls config, e.g. by a hash of the ca bundle it was built from.
+func NewInstrumentedRoundTripperWithTLSConfig(relatedResource string, metric *prometheus.CounterVec, key string, tlsConfig *tls.Config) http.RoundTripper {
 	return &instrumentedRoundTripper{
 		relatedResource: relatedResource,
-		wrapped:         transport,
+		wrapped:         getTransport(key, tlsConfig),
 		metric:          metric,
 	}
 }
 
+// getTransport returns the transport of a tls config, it's created from the default transport on first use so that
+// proxy settings and dial timeouts apply
+func getTransport(key string, tlsConfig *tls.Config) *http.Transport {
+	transportsLock.Lock()
+	defer transportsLock.Unlock()
+
+	transport, ok := transports[key]
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		transports[key] = transport
+	}
+	return transport
+}
+
 func (in *instrumentedRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
 	resp, err := in.wrapped.RoundTrip(r)
 	if resp != nil {
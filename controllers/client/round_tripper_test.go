@@ -0,0 +1,26 @@
+package client
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInstrumentedRoundTripperWithTLSConfig(t *testing.T) {
+	metric := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_requests"}, []string{"resource", "method", "status"})
+
+	first := NewInstrumentedRoundTripperWithTLSConfig("a", metric, "test-ca", &tls.Config{MinVersion: tls.VersionTLS12})
+	second := NewInstrumentedRoundTripperWithTLSConfig("b", metric, "test-ca", &tls.Config{MinVersion: tls.VersionTLS12})
+	other := NewInstrumentedRoundTripperWithTLSConfig("a", metric, "other-ca", &tls.Config{MinVersion: tls.VersionTLS12})
+
+	// round trippers with the same tls config share their connections
+	transport := first.(*instrumentedRoundTripper).wrapped
+	assert.Same(t, transport, second.(*instrumentedRoundTripper).wrapped)
+	assert.NotSame(t, transport, other.(*instrumentedRoundTripper).wrapped)
+
+	// proxy settings of the default transport apply
+	assert.NotNil(t, transport.(*http.Transport).Proxy)
+}
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// syncState collects the outcome of syncing a resource to its matching instances, it decides the reason of the
+// conditions and the sync status of each instance
+type syncState struct {
+	notReady    []string
+	errors      []string
+	unsupported int
+	previous    v1beta1.InstanceSyncStatusList
+	statuses    map[string]*v1beta1.InstanceSyncStatus
+	failed      map[string]bool
+}
+
+// unsupportedError is returned when an instance doesn't support the resource, syncing it is stalled until the
+// resource or the instance is changed
+type unsupportedError struct {
+	error
+}
+
+// newSyncState starts a sync, the previous status of an instance is kept until the instance is synced again
+func newSyncState(previous v1beta1.InstanceSyncStatusList) *syncState {
+	return &syncState{
+		previous: previous,
+		statuses: map[string]*v1beta1.InstanceSyncStatus{},
+		failed:   map[string]bool{},
+	}
+}
+
+func (s *syncState) getInstanceStatus(grafana *v1beta1.Grafana) *v1beta1.InstanceSyncStatus {
+	key := fmt.Sprintf("%v/%v", grafana.Namespace, grafana.Name)
+	if status, ok := s.statuses[key]; ok {
+		return status
+	}
+
+	status := &v1beta1.InstanceSyncStatus{
+		Instance: key,
+	}
+	if previous := s.previous.Find(key); previous != nil {
+		status = previous
+	}
+	s.statuses[key] = status
+	return status
+}
+
+func (s *syncState) addNotReady(grafana *v1beta1.Grafana) {
+	status := s.getInstanceStatus(grafana)
+	status.LastError = "instance not ready"
+	s.failed[status.Instance] = true
+	s.notReady = append(s.notReady, status.Instance)
+}
+
+func (s *syncState) addError(grafana *v1beta1.Grafana, err error) {
+	status := s.getInstanceStatus(grafana)
+	status.LastError = err.Error()
+	s.failed[status.Instance] = true
+	s.errors = append(s.errors, fmt.Sprintf("%v: %v", status.Instance, err))
+
+	var unsupported unsupportedError
+	if errors.As(err, &unsupported) {
+		s.unsupported++
+	}
+}
+
+// addSynced records the uid and the content hash of the resource in an instance, unless syncing it failed. The sync
+// time only moves when the content applied to the instance changed, so resyncs don't update the status.
+func (s *syncState) addSynced(grafana *v1beta1.Grafana, uid string, hash string) {
+	status := s.getInstanceStatus(grafana)
+	if s.failed[status.Instance] {
+		return
+	}
+
+	if status.LastSyncTime == nil || status.Uid != uid || status.Hash != hash {
+		now := metav1.Now()
+		status.LastSyncTime = &now
+	}
+	status.Uid = uid
+	status.Hash = hash
+	status.LastError = ""
+}
+
+// setPermissions records whether the permissions of the resource are applied in an instance, unless syncing it failed
+func (s *syncState) setPermissions(grafana *v1beta1.Grafana, applied bool) {
+	status := s.getInstanceStatus(grafana)
+	if s.failed[status.Instance] {
+		return
+	}
+	status.Permissions = applied
+}
+
+// success returns true if the resource was synced to all matching instances
+func (s *syncState) success() bool {
+	return len(s.errors) == 0 && len(s.notReady) == 0
+}
+
+// stalled returns true if all errors are caused by instances that don't support the resource, retrying won't help
+func (s *syncState) stalled() bool {
+	return s.unsupported > 0 && s.unsupported == len(s.errors)
+}
+
+// getReason returns the reason and message of the conditions
+func (s *syncState) getReason() (string, string) {
+	switch {
+	case s.stalled():
+		return v1beta1.ReasonUnsupported, strings.Join(s.errors, "; ")
+	case len(s.errors) > 0:
+		return v1beta1.ReasonSyncFailed, strings.Join(s.errors, "; ")
+	case len(s.notReady) > 0:
+		return v1beta1.ReasonInstancesNotReady, fmt.Sprintf("waiting for instances %v", strings.Join(s.notReady, ", "))
+	case len(s.statuses) == 0:
+		return v1beta1.ReasonNoMatchingInstances, "no grafana instances match the instance selector"
+	default:
+		return v1beta1.ReasonSynced, ""
+	}
+}
+
+// getInstances returns the sync status of the matching instances, instances that no longer match are dropped
+func (s *syncState) getInstances() v1beta1.InstanceSyncStatusList {
+	var instances v1beta1.InstanceSyncStatusList
+	for _, status := range s.statuses {
+		instances = append(instances, *status)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Instance < instances[j].Instance
+	})
+	return instances
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSyncState(t *testing.T) {
+	grafana := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}}
+
+	state := newSyncState(nil)
+	reason, _ := state.getReason()
+	assert.Equal(t, v1beta1.ReasonNoMatchingInstances, reason)
+	assert.True(t, state.success())
+
+	state.addSynced(grafana, "uid", "hash")
+	reason, message := state.getReason()
+	assert.Equal(t, v1beta1.ReasonSynced, reason)
+	assert.Empty(t, message)
+
+	state.addNotReady(grafana)
+	reason, message = state.getReason()
+	assert.Equal(t, v1beta1.ReasonInstancesNotReady, reason)
+	assert.Equal(t, "waiting for instances monitoring/grafana", message)
+	assert.False(t, state.success())
+
+	// errors take precedence over instances that are not ready
+	state.addError(grafana, fmt.Errorf("status: 500"))
+	reason, message = state.getReason()
+	assert.Equal(t, v1beta1.ReasonSyncFailed, reason)
+	assert.Equal(t, "monitoring/grafana: status: 500", message)
+}
+
+func TestSyncState_Instances(t *testing.T) {
+	healthy := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "healthy", Namespace: "monitoring"}}
+	failing := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "failing", Namespace: "monitoring"}}
+
+	lastSync := metav1.Now()
+	previous := v1beta1.InstanceSyncStatusList{
+		{Instance: "monitoring/failing", Uid: "uid", Hash: "old", LastSyncTime: &lastSync},
+		{Instance: "monitoring/removed", Uid: "uid", Hash: "old", LastSyncTime: &lastSync},
+	}
+
+	state := newSyncState(previous)
+	state.addSynced(healthy, "uid", "new")
+	state.addError(failing, fmt.Errorf("status: 500"))
+	// a failed instance isn't marked synced by later steps
+	state.addSynced(failing, "uid", "new")
+
+	instances := state.getInstances()
+	assert.Len(t, instances, 2)
+
+	assert.Equal(t, "monitoring/failing", instances[0].Instance)
+	assert.Equal(t, "old", instances[0].Hash)
+	assert.Equal(t, "status: 500", instances[0].LastError)
+	assert.Equal(t, &lastSync, instances[0].LastSyncTime)
+
+	assert.Equal(t, "monitoring/healthy", instances[1].Instance)
+	assert.Equal(t, "new", instances[1].Hash)
+	assert.Empty(t, instances[1].LastError)
+	assert.NotNil(t, instances[1].LastSyncTime)
+
+	// the previous status is left untouched
+	assert.Empty(t, previous[0].LastError)
+}
+
+func TestSyncState_KeepsSyncTimeOfUnchangedContent(t *testing.T) {
+	grafana := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}}
+
+	lastSync := metav1.NewTime(metav1.Now().Add(-time.Hour))
+	previous := v1beta1.InstanceSyncStatusList{
+		{Instance: "monitoring/grafana", Uid: "uid", Hash: "hash", LastSyncTime: &lastSync},
+	}
+
+	state := newSyncState(previous)
+	state.addSynced(grafana, "uid", "hash")
+	assert.Equal(t, previous, state.getInstances())
+
+	state = newSyncState(previous)
+	state.addSynced(grafana, "uid", "changed")
+	instances := state.getInstances()
+	assert.Equal(t, "changed", instances[0].Hash)
+	assert.True(t, instances[0].LastSyncTime.After(lastSync.Time))
+}
+
+func TestSyncState_KeepsPermissionsOfFailedInstances(t *testing.T) {
+	grafana := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}}
+	previous := v1beta1.InstanceSyncStatusList{
+		{Instance: "monitoring/grafana", Uid: "uid", Permissions: true},
+	}
+	assert.True(t, previous.HasPermissions(grafana))
+
+	// permissions that couldn't be reset are reset again on the next sync
+	state := newSyncState(previous)
+	state.addError(grafana, fmt.Errorf("unavailable"))
+	state.setPermissions(grafana, false)
+	assert.True(t, state.getInstances().HasPermissions(grafana))
+
+	state = newSyncState(previous)
+	state.addSynced(grafana, "uid", "")
+	state.setPermissions(grafana, false)
+	assert.False(t, state.getInstances().HasPermissions(grafana))
+}
+
+func TestSyncState_StalledOnUnsupportedInstances(t *testing.T) {
+	api := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "monitoring"}}
+	file := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "file", Namespace: "monitoring"}}
+
+	state := newSyncState(nil)
+	state.addSynced(api, "uid", "hash")
+	state.addError(file, unsupportedError{fmt.Errorf("not supported")})
+	reason, message := state.getReason()
+	assert.Equal(t, v1beta1.ReasonUnsupported, reason)
+	assert.Equal(t, "monitoring/file: not supported", message)
+	assert.True(t, state.stalled())
+	assert.False(t, state.success())
+
+	// other errors may go away when retrying
+	state.addError(api, fmt.Errorf("status: 500"))
+	reason, _ = state.getReason()
+	assert.Equal(t, v1beta1.ReasonSyncFailed, reason)
+	assert.False(t, state.stalled())
+}
@@ -11,6 +11,11 @@ const (
 	GrafanaPluginsPath      = "/var/lib/grafana/plugins"
 	GrafanaProvisioningPath = "/etc/grafana/provisioning/"
 
+	// Provisioning files, used when the provisioning mode is file
+	GrafanaProvisioningDashboardsPath  = GrafanaProvisioningPath + "dashboards/"
+	GrafanaProvisioningDatasourcesPath = GrafanaProvisioningPath + "datasources/"
+	GrafanaProvisionedDashboardsPath   = GrafanaProvisioningPath + "operator-dashboards/"
+
 	// Grafana env vars and admin user
 	DefaultAdminUser           = "admin"
 	GrafanaAdminUserEnvVar     = "GF_SECURITY_ADMIN_USER"
@@ -23,12 +28,14 @@ const (
 	GrafanaServerProtocol     = "http"
 
 	// Data storage
-	GrafanaProvisionPluginVolumeName    = "grafana-provision-plugins"
-	GrafanaPluginsVolumeName            = "grafana-plugins"
-	GrafanaProvisionDashboardVolumeName = "grafana-provision-dashboards"
-	GrafanaProvisionNotifierVolumeName  = "grafana-provision-notifiers"
-	GrafanaLogsVolumeName               = "grafana-logs"
-	GrafanaDataVolumeName               = "grafana-data"
-	SecretsMountDir                     = "/etc/grafana-secrets/" // #nosec G101
-	ConfigMapsMountDir                  = "/etc/grafana-configmaps/"
+	GrafanaProvisionPluginVolumeName     = "grafana-provision-plugins"
+	GrafanaPluginsVolumeName             = "grafana-plugins"
+	GrafanaProvisionDashboardVolumeName  = "grafana-provision-dashboards"
+	GrafanaProvisionProviderVolumeName   = "grafana-provision-providers"
+	GrafanaProvisionDatasourceVolumeName = "grafana-provision-datasources"
+	GrafanaProvisionNotifierVolumeName   = "grafana-provision-notifiers"
+	GrafanaLogsVolumeName                = "grafana-logs"
+	GrafanaDataVolumeName                = "grafana-data"
+	SecretsMountDir                      = "/etc/grafana-secrets/" // #nosec G101
+	ConfigMapsMountDir                   = "/etc/grafana-configmaps/"
 )
@@ -0,0 +1,21 @@
+package config
+
+import "fmt"
+
+// DashboardProvidersFileName is the name of the file that configures where Grafana provisions dashboards from
+const DashboardProvidersFileName = "grafana-operator.yaml"
+
+// GetDashboardProviders returns the dashboard provider that loads the dashboards rendered by the operator. Deleted
+// files remove their dashboard, changes can only be made through the dashboard crs.
+func GetDashboardProviders() string {
+	return fmt.Sprintf(`apiVersion: 1
+providers:
+  - name: grafana-operator
+    type: file
+    disableDeletion: false
+    allowUiUpdates: false
+    updateIntervalSeconds: 10
+    options:
+      path: %v
+`, GrafanaProvisionedDashboardsPath)
+}
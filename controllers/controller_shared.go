@@ -4,22 +4,74 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 
 	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
 	"github.com/grafana-operator/grafana-operator-experimental/controllers/model"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func GetMatchingInstances(ctx context.Context, k8sClient client.Client, labelSelector *v1.LabelSelector) (v1beta1.GrafanaList, error) {
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// GetMatchingInstances returns all Grafana instances matching the label selector. When a namespace selector is
+// given, only instances in namespaces matching that selector are returned.
+func GetMatchingInstances(ctx context.Context, k8sClient client.Client, labelSelector *v1.LabelSelector, namespaceSelector *v1.LabelSelector) (v1beta1.GrafanaList, error) {
 	var list v1beta1.GrafanaList
+
+	selector, err := v1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return list, fmt.Errorf("invalid instance selector: %w", err)
+	}
+
 	opts := []client.ListOption{
-		client.MatchingLabels(labelSelector.MatchLabels),
+		client.MatchingLabelsSelector{Selector: selector},
+	}
+
+	err = k8sClient.List(ctx, &list, opts...)
+	if err != nil || namespaceSelector == nil {
+		return list, err
 	}
 
-	err := k8sClient.List(ctx, &list, opts...)
-	return list, err
+	namespaces, err := getMatchingNamespaces(ctx, k8sClient, namespaceSelector)
+	if err != nil {
+		return v1beta1.GrafanaList{}, err
+	}
+
+	items := []v1beta1.Grafana{}
+	for _, grafana := range list.Items {
+		if _, ok := namespaces[grafana.Namespace]; ok {
+			items = append(items, grafana)
+		}
+	}
+	list.Items = items
+
+	return list, nil
+}
+
+func getMatchingNamespaces(ctx context.Context, k8sClient client.Client, namespaceSelector *v1.LabelSelector) (map[string]struct{}, error) {
+	selector, err := v1.LabelSelectorAsSelector(namespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid instance namespace selector: %w", err)
+	}
+
+	var list corev1.NamespaceList
+	err = k8sClient.List(ctx, &list, client.MatchingLabelsSelector{Selector: selector})
+	if errors.IsForbidden(err) {
+		return nil, fmt.Errorf("instance namespace selector requires permission to list namespaces, which namespace scoped operators don't have: %w", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make(map[string]struct{}, len(list.Items))
+	for _, namespace := range list.Items {
+		namespaces[namespace.Name] = struct{}{}
+	}
+	return namespaces, nil
 }
 
 func ReconcilePlugins(ctx context.Context, k8sClient client.Client, scheme *runtime.Scheme, grafana *v1beta1.Grafana, plugins v1beta1.PluginList, resource string) error {
@@ -50,3 +102,108 @@ func ReconcilePlugins(ctx context.Context, k8sClient client.Client, scheme *runt
 
 	return nil
 }
+
+// getProvisioningFileName returns the name of the provisioning file of a resource, names and namespaces can't contain
+// underscores so the file names are unique
+func getProvisioningFileName(namespace string, name string, extension string) string {
+	return fmt.Sprintf("%v_%v.%v", namespace, name, extension)
+}
+
+// ReconcileProvisionedDashboard writes the file of a dashboard to one of the config maps the instance provisions
+// dashboards from, Grafana picks up changes without a restart. A file stays in its config map as long as it fits,
+// new files go to the first config map with room. Empty content removes the file.
+func ReconcileProvisionedDashboard(ctx context.Context, k8sClient client.Client, scheme *runtime.Scheme, grafana *v1beta1.Grafana, fileName string, content []byte) error {
+	configMaps := model.GetProvisionedDashboardsConfigMaps(grafana, scheme)
+	current := -1
+	for i, configMap := range configMaps {
+		selector := client.ObjectKey{
+			Namespace: configMap.Namespace,
+			Name:      configMap.Name,
+		}
+
+		err := k8sClient.Get(ctx, selector, configMap)
+		if err != nil {
+			return err
+		}
+		if _, found := configMap.Data[fileName]; found {
+			current = i
+		}
+	}
+
+	if current >= 0 {
+		configMap := configMaps[current]
+		switch {
+		case content != nil && configMap.Data[fileName] == string(content):
+			return nil
+		case content != nil && fitsProvisionedDashboards(configMap, fileName, content):
+			configMap.Data[fileName] = string(content)
+			return k8sClient.Update(ctx, configMap)
+		}
+
+		// the file is removed before it is written to another config map, the same path in two config maps of the
+		// projected volume conflicts
+		delete(configMap.Data, fileName)
+		err := k8sClient.Update(ctx, configMap)
+		if err != nil || content == nil {
+			return err
+		}
+	}
+
+	if content == nil {
+		return nil
+	}
+
+	for i, configMap := range configMaps {
+		if i == current || !fitsProvisionedDashboards(configMap, fileName, content) {
+			continue
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[fileName] = string(content)
+		return k8sClient.Update(ctx, configMap)
+	}
+
+	return fmt.Errorf("dashboard file %v doesn't fit into the %d config maps of instance %v/%v", fileName, len(configMaps), grafana.Namespace, grafana.Name)
+}
+
+// fitsProvisionedDashboards returns true if the config map stays below its maximum size with the file written to it
+func fitsProvisionedDashboards(configMap *corev1.ConfigMap, fileName string, content []byte) bool {
+	size := len(fileName) + len(content)
+	for key, value := range configMap.Data {
+		if key != fileName {
+			size += len(key) + len(value)
+		}
+	}
+	return size <= model.ProvisionedDashboardsConfigMapSize
+}
+
+// ReconcileProvisionedDatasource writes the file of a datasource to the secret the instance provisions datasources
+// from, changes restart the instance. Empty content removes the file.
+func ReconcileProvisionedDatasource(ctx context.Context, k8sClient client.Client, scheme *runtime.Scheme, grafana *v1beta1.Grafana, fileName string, content []byte) error {
+	secret := model.GetProvisionedDatasourcesSecret(grafana, scheme)
+	selector := client.ObjectKey{
+		Namespace: secret.Namespace,
+		Name:      secret.Name,
+	}
+
+	err := k8sClient.Get(ctx, selector, secret)
+	if err != nil {
+		return err
+	}
+
+	existing, found := secret.Data[fileName]
+	switch {
+	case content == nil && found:
+		delete(secret.Data, fileName)
+	case content != nil && !bytes.Equal(existing, content):
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[fileName] = content
+	default:
+		return nil
+	}
+
+	return k8sClient.Update(ctx, secret)
+}
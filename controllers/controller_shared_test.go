@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/model"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetMatchingInstances(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	grafana := func(namespace, name string, labels map[string]string) *v1beta1.Grafana {
+		return &v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    labels,
+			},
+		}
+	}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"tenant": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"tenant": "b"}}},
+		grafana("team-a", "prod", map[string]string{"dashboards": "grafana", "env": "prod"}),
+		grafana("team-a", "staging", map[string]string{"dashboards": "grafana", "env": "staging"}),
+		grafana("team-b", "prod", map[string]string{"dashboards": "grafana", "env": "prod"}),
+	).Build()
+
+	tests := []struct {
+		name              string
+		selector          *metav1.LabelSelector
+		namespaceSelector *metav1.LabelSelector
+		want              []string
+	}{
+		{
+			name: "match labels only",
+			selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"dashboards": "grafana"},
+			},
+			want: []string{"team-a/prod", "team-a/staging", "team-b/prod"},
+		},
+		{
+			name: "match expressions exclude instances",
+			selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"dashboards": "grafana"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "env", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"staging"}},
+				},
+			},
+			want: []string{"team-a/prod", "team-b/prod"},
+		},
+		{
+			name: "namespace selector restricts namespaces",
+			selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"env": "prod"},
+			},
+			namespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tenant": "b"},
+			},
+			want: []string{"team-b/prod"},
+		},
+		{
+			name:     "nil selector matches nothing",
+			selector: nil,
+			want:     []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list, err := GetMatchingInstances(context.Background(), k8sClient, tt.selector, tt.namespaceSelector)
+			assert.NoError(t, err)
+
+			got := []string{}
+			for _, instance := range list.Items {
+				got = append(got, instance.Namespace+"/"+instance.Name)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestReconcileProvisionedDashboard(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	grafana := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}}
+	k8sClient := getProvisionedDashboardsTestClient(scheme, grafana)
+
+	fileName := getProvisioningFileName("team-a", "overview", "json")
+	assert.Equal(t, "team-a_overview.json", fileName)
+
+	err := ReconcileProvisionedDashboard(context.Background(), k8sClient, scheme, grafana, fileName, []byte(`{"uid":"overview"}`))
+	assert.NoError(t, err)
+
+	configMap := &corev1.ConfigMap{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "monitoring", Name: "grafana-dashboards"}, configMap))
+	assert.Equal(t, `{"uid":"overview"}`, configMap.Data[fileName])
+
+	err = ReconcileProvisionedDashboard(context.Background(), k8sClient, scheme, grafana, fileName, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "monitoring", Name: "grafana-dashboards"}, configMap))
+	assert.NotContains(t, configMap.Data, fileName)
+}
+
+func TestReconcileProvisionedDashboard_SpreadsAcrossConfigMaps(t *testing.T) {
+	size := model.ProvisionedDashboardsConfigMapSize
+	model.ProvisionedDashboardsConfigMapSize = 64
+	defer func() {
+		model.ProvisionedDashboardsConfigMapSize = size
+	}()
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	grafana := &v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"}}
+	k8sClient := getProvisionedDashboardsTestClient(scheme, grafana)
+	getConfigMap := func(name string) *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{}
+		assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "monitoring", Name: name}, configMap))
+		return configMap
+	}
+
+	content := []byte(strings.Repeat("a", 40))
+	assert.NoError(t, ReconcileProvisionedDashboard(context.Background(), k8sClient, scheme, grafana, "a.json", content))
+	assert.NoError(t, ReconcileProvisionedDashboard(context.Background(), k8sClient, scheme, grafana, "b.json", content))
+	assert.Contains(t, getConfigMap("grafana-dashboards").Data, "a.json")
+	assert.Contains(t, getConfigMap("grafana-dashboards-1").Data, "b.json")
+
+	assert.NoError(t, ReconcileProvisionedDashboard(context.Background(), k8sClient, scheme, grafana, "c.json", []byte(strings.Repeat("c", 10))))
+	assert.Contains(t, getConfigMap("grafana-dashboards").Data, "c.json")
+
+	// a file that outgrows its config map moves to one with room
+	assert.NoError(t, ReconcileProvisionedDashboard(context.Background(), k8sClient, scheme, grafana, "a.json", []byte(strings.Repeat("a", 45))))
+	assert.NotContains(t, getConfigMap("grafana-dashboards").Data, "a.json")
+	assert.Contains(t, getConfigMap("grafana-dashboards-2").Data, "a.json")
+
+	err := ReconcileProvisionedDashboard(context.Background(), k8sClient, scheme, grafana, "d.json", []byte(strings.Repeat("d", 100)))
+	assert.Error(t, err)
+}
+
+func getProvisionedDashboardsTestClient(scheme *runtime.Scheme, grafana *v1beta1.Grafana) client.Client {
+	var objects []client.Object
+	for _, configMap := range model.GetProvisionedDashboardsConfigMaps(grafana, scheme) {
+		objects = append(objects, configMap)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
@@ -22,6 +22,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,17 +37,32 @@ import (
 	"github.com/grafana-operator/grafana-operator-experimental/controllers/fetchers"
 	"github.com/grafana-operator/grafana-operator-experimental/controllers/metrics"
 	grapi "github.com/grafana/grafana-api-golang-client"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const (
 	initialSyncDelay = "10s"
 	syncBatchSize    = 100
+
+	// field indexes used to find the dashboards referencing a config map or secret
+	dashboardConfigMapIndexKey = "configMapReferences"
+	dashboardSecretIndexKey    = "secretReferences"
+	// field index used to find the dashboards referencing a folder, indexed by namespace/name of the folder
+	dashboardFolderIndexKey = "folderReference"
 )
 
 // GrafanaDashboardReconciler reconciles a GrafanaDashboard object
@@ -52,10 +71,12 @@ type GrafanaDashboardReconciler struct {
 	Log       logr.Logger
 	Scheme    *runtime.Scheme
 	Discovery discovery.DiscoveryInterface
+	Recorder  record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadashboards,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadashboards/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 //+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadashboards/finalizers,verbs=update
 
 func (r *GrafanaDashboardReconciler) syncDashboards(ctx context.Context) (ctrl.Result, error) {
@@ -90,6 +111,17 @@ func (r *GrafanaDashboardReconciler) syncDashboards(ctx context.Context) (ctrl.R
 
 	// delete all dashboards that no longer have a cr
 	for grafana, dashboards := range dashboardsToDelete {
+		if grafana.IsFileProvisioning() {
+			for _, dashboard := range dashboards {
+				err = r.onProvisionedDashboardDeleted(ctx, grafana, dashboard.Namespace(), dashboard.Name())
+				if err != nil {
+					return ctrl.Result{Requeue: false}, err
+				}
+				dashboardsSynced += 1
+			}
+			continue
+		}
+
 		grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, grafana)
 		if err != nil {
 			return ctrl.Result{Requeue: true}, err
@@ -116,6 +148,7 @@ func (r *GrafanaDashboardReconciler) syncDashboards(ctx context.Context) (ctrl.R
 			grafana.Status.Dashboards = grafana.Status.Dashboards.Remove(namespace, name)
 			dashboardsSynced += 1
 		}
+		client2.GetInventory(grafana).Invalidate()
 
 		// one update per grafana - this will trigger a reconcile of the grafana controller
 		// so we should minimize those updates
@@ -165,7 +198,7 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	}, dashboard)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			err = r.onDashboardDeleted(ctx, req.Namespace, req.Name)
+			err = r.onDashboardDeleted(ctx, req.Namespace, req.Name, nil)
 			if err != nil {
 				return ctrl.Result{RequeueAfter: RequeueDelay}, err
 			}
@@ -175,6 +208,17 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{RequeueAfter: RequeueDelay}, err
 	}
 
+	deleting, err := reconcileFinalizer(ctx, r.Client, dashboard, func() error {
+		return r.finalizeDashboard(ctx, dashboard)
+	})
+	if err != nil {
+		controllerLog.Error(err, "error finalizing grafana dashboard cr", "name", dashboard.Name, "namespace", dashboard.Namespace)
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+	if deleting {
+		return ctrl.Result{}, nil
+	}
+
 	instances, err := r.GetMatchingDashboardInstances(ctx, dashboard, r.Client)
 	if err != nil {
 		controllerLog.Error(err, "could not find matching instances", "name", dashboard.Name, "namespace", dashboard.Namespace)
@@ -183,7 +227,11 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	controllerLog.Info("found matching Grafana instances for dashboard", "count", len(instances.Items))
 
-	success := true
+	// uid conflicts are detected again in every instance
+	dashboard.Status.UidConflict = ""
+
+	previous := dashboard.Status.DeepCopy()
+	state := newSyncState(dashboard.Status.Instances)
 	for _, grafana := range instances.Items {
 		// check if this is a cross namespace import
 		if grafana.Namespace != dashboard.Namespace && !dashboard.IsAllowCrossNamespaceImport() {
@@ -195,7 +243,7 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		// the instance or route might not yet be ready
 		if grafana.Status.Stage != v1beta1.OperatorStageComplete || grafana.Status.StageStatus != v1beta1.OperatorStageResultSuccess {
 			controllerLog.Info("grafana instance not ready", "grafana", grafana.Name)
-			success = false
+			state.addNotReady(&grafana)
 			continue
 		}
 
@@ -206,132 +254,200 @@ func (r *GrafanaDashboardReconciler) Reconcile(ctx context.Context, req ctrl.Req
 			err = ReconcilePlugins(ctx, r.Client, r.Scheme, &grafana, dashboard.Spec.Plugins, fmt.Sprintf("%v-dashboard", dashboard.Name))
 			if err != nil {
 				controllerLog.Error(err, "error reconciling plugins", "dashboard", dashboard.Name, "grafana", grafana.Name)
-				success = false
+				state.addError(&grafana, err)
 			}
 		}
 
 		// then import the dashboard into the matching grafana instances
-		err = r.onDashboardCreated(ctx, &grafana, dashboard)
+		var hash string
+		if grafana.IsFileProvisioning() {
+			hash, err = r.onDashboardProvisioned(ctx, &grafana, dashboard)
+		} else {
+			hash, err = r.onDashboardCreated(ctx, &grafana, dashboard)
+		}
 		if err != nil {
 			controllerLog.Error(err, "error reconciling dashboard", "dashboard", dashboard.Name, "grafana", grafana.Name)
-			success = false
+			state.addError(&grafana, err)
+		}
+		state.addSynced(&grafana, grafana.Status.Dashboards.GetUid(dashboard.Namespace, dashboard.Name), hash)
+		state.setPermissions(&grafana, len(dashboard.Spec.Permissions) > 0 && !grafana.IsFileProvisioning())
+	}
+	dashboard.Status.Instances = state.getInstances()
+
+	// a uid conflict can only be resolved by changing one of the dashboards
+	reason, message := state.getReason()
+	if dashboard.Status.UidConflict != "" {
+		dashboard.Status.SetSyncConditions(dashboard.Generation, v1beta1.ReasonUidConflict, dashboard.Status.UidConflict, true)
+	} else {
+		dashboard.Status.SetSyncConditions(dashboard.Generation, reason, message, state.stalled())
+	}
+
+	// the status is written once all instances were synced, resyncs that change nothing don't trigger a reconcile
+	if !reflect.DeepEqual(previous, &dashboard.Status) {
+		err = r.Client.Status().Update(ctx, dashboard)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: RequeueDelay}, err
 		}
 	}
 
 	// if the dashboard was successfully synced in all instances, wait for its re-sync period
-	if success {
+	if state.success() {
 		return ctrl.Result{RequeueAfter: dashboard.GetResyncPeriod()}, nil
 	}
 
 	return ctrl.Result{RequeueAfter: RequeueDelay}, nil
 }
 
-func (r *GrafanaDashboardReconciler) onDashboardDeleted(ctx context.Context, namespace string, name string) error {
-	list := v1beta1.GrafanaList{}
-	var opts []client.ListOption
-	err := r.Client.List(ctx, &list, opts...)
+// onDashboardDeleted removes the dashboard from all instances it was synced to
+func (r *GrafanaDashboardReconciler) onDashboardDeleted(ctx context.Context, namespace string, name string, synced v1beta1.InstanceSyncStatusList) error {
+	instances, err := getSyncedInstances(ctx, r.Client, namespace, name, synced, dashboardStatusList)
 	if err != nil {
 		return err
 	}
 
-	for _, grafana := range list.Items {
-		if found, uid := grafana.Status.Dashboards.Find(namespace, name); found {
-			grafana := grafana
-			grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, &grafana)
-			if err != nil {
-				return err
-			}
+	for _, instance := range instances {
+		if instance.grafana.IsFileProvisioning() {
+			err = r.onProvisionedDashboardDeleted(ctx, instance.grafana, namespace, name)
+		} else {
+			err = r.deleteDashboard(ctx, instance.grafana, namespace, name, instance.uid)
+		}
+		if err != nil {
+			return err
+		}
+	}
 
-			dash, err := grafanaClient.DashboardByUID(*uid)
-			if err != nil {
-				if !strings.Contains(err.Error(), "status: 404") {
-					return err
-				}
-			}
+	return nil
+}
 
-			err = grafanaClient.DeleteDashboardByUID(*uid)
-			if err != nil {
-				if !strings.Contains(err.Error(), "status: 404") {
-					return err
-				}
-			}
+// deleteDashboard removes the dashboard from an instance, the folder of the dashboard is removed too once it is empty
+func (r *GrafanaDashboardReconciler) deleteDashboard(ctx context.Context, grafana *v1beta1.Grafana, namespace string, name string, uid string) error {
+	grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, grafana)
+	if err != nil {
+		return err
+	}
 
-			if dash != nil && dash.Meta.Folder > 0 {
-				resp, err := r.DeleteFolderIfEmpty(grafanaClient, dash.Folder)
-				if err != nil {
-					return err
-				}
-				if resp.StatusCode == 200 {
-					r.Log.Info("unused folder successfully removed")
-				}
-				if resp.StatusCode == 432 {
-					r.Log.Info("folder still in use by other dashboards")
-				}
-			}
+	dash, err := grafanaClient.DashboardByUID(uid)
+	if err != nil {
+		if !strings.Contains(err.Error(), "status: 404") {
+			return err
+		}
+	}
 
-			if grafana.IsInternal() {
-				err = ReconcilePlugins(ctx, r.Client, r.Scheme, &grafana, nil, fmt.Sprintf("%v-dashboard", name))
-				if err != nil {
-					return err
-				}
-			}
+	err = grafanaClient.DeleteDashboardByUID(uid)
+	if err != nil {
+		if !strings.Contains(err.Error(), "status: 404") {
+			return err
+		}
+	}
+	client2.GetInventory(grafana).Invalidate()
 
-			grafana.Status.Dashboards = grafana.Status.Dashboards.Remove(namespace, name)
-			err = r.Client.Status().Update(ctx, &grafana)
-			if err != nil {
-				return err
-			}
+	if dash != nil && dash.Meta.Folder > 0 {
+		resp, err := r.DeleteFolderIfEmpty(grafanaClient, grafana, dash.Folder)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == 200 {
+			r.Log.Info("unused folder successfully removed")
+		}
+		if resp.StatusCode == 432 {
+			r.Log.Info("folder still in use by other dashboards")
 		}
 	}
 
-	return nil
+	if grafana.IsInternal() {
+		err = ReconcilePlugins(ctx, r.Client, r.Scheme, grafana, nil, fmt.Sprintf("%v-dashboard", name))
+		if err != nil {
+			return err
+		}
+	}
+
+	grafana.Status.Dashboards = grafana.Status.Dashboards.Remove(namespace, name)
+	return r.Client.Status().Update(ctx, grafana)
 }
 
-func (r *GrafanaDashboardReconciler) onDashboardCreated(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard) error {
-	dashboardJson, err := r.fetchDashboardJson(cr)
-	if err != nil {
-		return err
+// finalizeDashboard removes the dashboard from the instances before the CR is deleted, unless the deletion policy
+// retains it
+func (r *GrafanaDashboardReconciler) finalizeDashboard(ctx context.Context, cr *v1beta1.GrafanaDashboard) error {
+	if cr.GetDeletionPolicy() == v1beta1.DeletionPolicyRetain {
+		return releaseContent(ctx, r.Client, cr.Namespace, cr.Name, dashboardStatusList)
 	}
+	return r.onDashboardDeleted(ctx, cr.Namespace, cr.Name, cr.Status.Instances)
+}
 
-	dashboardJson, err = r.resolveDatasources(cr, dashboardJson)
+func (r *GrafanaDashboardReconciler) onDashboardCreated(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard) (string, error) {
+	dashboardJson, err := r.fetchCachedDashboardJson(ctx, grafana, cr, cr, v1beta1.GroupVersion.WithKind("GrafanaDashboard"))
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if grafana.IsExternal() && cr.Spec.Plugins != nil {
-		return fmt.Errorf("external grafana instances don't support plugins, please remove spec.plugins from your dashboard cr")
+		return "", fmt.Errorf("external grafana instances don't support plugins, please remove spec.plugins from your dashboard cr")
 	}
 
-	// Dashboards come from different sources, whereas Spec.Json is used to calculate hash
-	// So, we should keep the field updated to make sure changes in dashboards get noticed
-	cr.Spec.Json = string(dashboardJson)
-
 	grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, grafana)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	// update/create the dashboard if it doesn't exist in the instance or has been changed
-	exists, err := r.Exists(grafanaClient, cr)
+	dashboardJson, err = r.resolveDatasources(ctx, grafana, grafanaClient, cr, dashboardJson)
 	if err != nil {
-		return err
-	}
-	if exists && cr.Unchanged() {
-		return nil
+		return "", err
 	}
 
+	// dashboards come from different sources, the hash of the fetched json is used to notice changes in any of them
+	hash := cr.HashContent(dashboardJson)
+
 	var dashboardFromJson map[string]interface{}
 	err = json.Unmarshal(dashboardJson, &dashboardFromJson)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	jsonUid, _ := dashboardFromJson["uid"].(string)
+	uid := cr.GetUid(jsonUid)
+
+	if conflict := getDashboardUidConflict(grafana, cr, uid); conflict != "" {
+		err = fmt.Errorf("uid %v is already used by dashboard %v in instance %v/%v", uid, conflict, grafana.Namespace, grafana.Name)
+		cr.Status.UidConflict = err.Error()
+		return "", err
+	}
+
+	// the uid of the dashboard changed since it was imported into the instance
+	_, previousUid := grafana.Status.Dashboards.Find(cr.Namespace, cr.Name)
+	uidChanged := previousUid != nil && *previousUid != uid
+
+	// update/create the dashboard if it doesn't exist in the instance or has been changed
+	id, err := r.ExistingId(grafanaClient, uid)
+	if err != nil {
+		return "", err
+	}
+	if id != nil && cr.Unchanged(grafana, hash) && !uidChanged {
+		revert, err := r.reconcileDrift(grafanaClient, grafana, cr, uid, dashboardJson)
+		if err != nil {
+			return "", err
+		}
+		if !revert {
+			return hash, ReconcileDashboardPermissions(grafanaClient, *id, cr.Spec.Permissions, cr.Status.Instances.HasPermissions(grafana))
+		}
 	}
 
-	folderID, err := r.GetOrCreateFolder(grafanaClient, cr)
+	folderID, err := r.GetOrCreateFolder(grafanaClient, grafana, cr)
 	if err != nil {
-		return errors.NewInternalError(err)
+		return "", errors.NewInternalError(err)
+	}
+
+	// remove the dashboard under its previous uid first, Grafana doesn't allow two dashboards with the same title in
+	// a folder
+	if uidChanged {
+		err = grafanaClient.DeleteDashboardByUID(*previousUid)
+		if err != nil && !strings.Contains(err.Error(), "status: 404") {
+			return "", err
+		}
+		client2.GetInventory(grafana).Invalidate()
+		r.Log.Info("moved dashboard to new uid", "dashboard", cr.Name, "grafana", grafana.Name, "previous", *previousUid, "uid", uid)
 	}
 
-	dashboardFromJson["uid"] = string(cr.UID)
+	dashboardFromJson["uid"] = uid
 	resp, err := grafanaClient.NewDashboard(grapi.Dashboard{
 		Meta: grapi.DashboardMeta{
 			IsStarred: false,
@@ -344,43 +460,196 @@ func (r *GrafanaDashboardReconciler) onDashboardCreated(ctx context.Context, gra
 		Message:   "",
 	})
 	if err != nil {
-		return err
+		return "", err
 	}
+	client2.GetInventory(grafana).Invalidate()
 
 	if resp.Status != "success" {
-		return errors.NewBadRequest(fmt.Sprintf("error creating dashboard, status was %v", resp.Status))
+		return "", errors.NewBadRequest(fmt.Sprintf("error creating dashboard, status was %v", resp.Status))
 	}
 
-	grafana.Status.Dashboards = grafana.Status.Dashboards.Add(cr.Namespace, cr.Name, resp.UID)
+	grafana.Status.Dashboards = grafana.Status.Dashboards.Remove(cr.Namespace, cr.Name).Add(cr.Namespace, cr.Name, resp.UID)
 	err = r.Client.Status().Update(ctx, grafana)
+	if err != nil {
+		return "", err
+	}
+
+	// changes made in the instance were overwritten
+	setDashboardDrift(cr, fmt.Sprintf("%v/%v", grafana.Namespace, grafana.Name), nil)
+	cr.Status.Hash = hash
+
+	return hash, ReconcileDashboardPermissions(grafanaClient, resp.ID, cr.Spec.Permissions, cr.Status.Instances.HasPermissions(grafana))
+}
+
+// onDashboardProvisioned renders the dashboard into the config map the instance provisions dashboards from. Grafana
+// reloads the file on its own. Folders and permissions require the api, dashboards using them are rejected, drift
+// detection doesn't apply.
+func (r *GrafanaDashboardReconciler) onDashboardProvisioned(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard) (string, error) {
+	if cr.Spec.FolderTitle != "" || cr.Spec.FolderRef != nil || len(cr.Spec.Permissions) > 0 {
+		return "", unsupportedError{fmt.Errorf("folders and permissions are not supported by instances with file provisioning")}
+	}
+
+	dashboardJson, err := r.fetchCachedDashboardJson(ctx, grafana, cr, cr, v1beta1.GroupVersion.WithKind("GrafanaDashboard"))
+	if err != nil {
+		return "", err
+	}
+
+	dashboardJson, err = r.resolveDatasources(ctx, grafana, nil, cr, dashboardJson)
+	if err != nil {
+		return "", err
+	}
+	hash := cr.HashContent(dashboardJson)
+
+	var dashboardFromJson map[string]interface{}
+	err = json.Unmarshal(dashboardJson, &dashboardFromJson)
+	if err != nil {
+		return "", err
+	}
+
+	jsonUid, _ := dashboardFromJson["uid"].(string)
+	uid := cr.GetUid(jsonUid)
+
+	if conflict := getDashboardUidConflict(grafana, cr, uid); conflict != "" {
+		err = fmt.Errorf("uid %v is already used by dashboard %v in instance %v/%v", uid, conflict, grafana.Namespace, grafana.Name)
+		cr.Status.UidConflict = err.Error()
+		return "", err
+	}
+
+	// ids are assigned by the instance
+	delete(dashboardFromJson, "id")
+	dashboardFromJson["uid"] = uid
+	content, err := json.Marshal(dashboardFromJson)
+	if err != nil {
+		return "", err
+	}
+
+	err = ReconcileProvisionedDashboard(ctx, r.Client, r.Scheme, grafana, getProvisioningFileName(cr.Namespace, cr.Name, "json"), content)
+	if err != nil {
+		return "", err
+	}
+
+	if found, previousUid := grafana.Status.Dashboards.Find(cr.Namespace, cr.Name); !found || *previousUid != uid {
+		grafana.Status.Dashboards = grafana.Status.Dashboards.Remove(cr.Namespace, cr.Name).Add(cr.Namespace, cr.Name, uid)
+		err = r.Client.Status().Update(ctx, grafana)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	cr.Status.Hash = hash
+	return hash, nil
+}
+
+// onProvisionedDashboardDeleted removes the file of a deleted dashboard, Grafana removes the dashboard with it
+func (r *GrafanaDashboardReconciler) onProvisionedDashboardDeleted(ctx context.Context, grafana *v1beta1.Grafana, namespace string, name string) error {
+	err := ReconcileProvisionedDashboard(ctx, r.Client, r.Scheme, grafana, getProvisioningFileName(namespace, name, "json"), nil)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	err = ReconcilePlugins(ctx, r.Client, r.Scheme, grafana, nil, fmt.Sprintf("%v-dashboard", name))
 	if err != nil {
 		return err
 	}
 
-	return r.UpdateStatus(ctx, cr)
+	grafana.Status.Dashboards = grafana.Status.Dashboards.Remove(namespace, name)
+	return r.Client.Status().Update(ctx, grafana)
 }
 
-// map data sources that are required in the dashboard to data sources that exist in the instance
-func (r *GrafanaDashboardReconciler) resolveDatasources(dashboard *v1beta1.GrafanaDashboard, dashboardJson []byte) ([]byte, error) {
+// map data sources that are required in the dashboard to data sources that exist in the instance, the client is nil
+// for instances provisioned from files
+func (r *GrafanaDashboardReconciler) resolveDatasources(ctx context.Context, grafana *v1beta1.Grafana, grafanaClient *grapi.Client, dashboard *v1beta1.GrafanaDashboard, dashboardJson []byte) ([]byte, error) {
 	if len(dashboard.Spec.Datasources) == 0 {
 		return dashboardJson, nil
 	}
 
+	references := map[string]datasourceReference{}
 	for _, input := range dashboard.Spec.Datasources {
-		if input.DatasourceName == "" || input.InputName == "" {
+		if input.InputName == "" || (input.DatasourceName == "" && input.DatasourceRef == nil) {
 			return nil, fmt.Errorf("invalid datasource input rule in dashboard %v/%v, input or datasource empty", dashboard.Namespace, dashboard.Name)
 		}
 
 		searchValue := fmt.Sprintf("${%s}", input.InputName)
+
+		if input.DatasourceRef != nil {
+			ref, err := r.getDatasourceReference(ctx, grafana, grafanaClient, dashboard, input.DatasourceRef)
+			if err != nil {
+				return nil, err
+			}
+			references[searchValue] = *ref
+			continue
+		}
+
 		dashboardJson = bytes.ReplaceAll(dashboardJson, []byte(searchValue), []byte(input.DatasourceName))
 	}
 
+	return replaceDatasourceReferences(dashboardJson, references)
+}
+
+// getDatasourceReference looks up the uid and type of a GrafanaDatasource in an instance
+func (r *GrafanaDashboardReconciler) getDatasourceReference(ctx context.Context, grafana *v1beta1.Grafana, grafanaClient *grapi.Client, dashboard *v1beta1.GrafanaDashboard, ref *v1beta1.GrafanaDatasourceReference) (*datasourceReference, error) {
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = dashboard.Namespace
+	}
+
+	found, uid := grafana.Status.Datasources.Find(namespace, ref.Name)
+	if !found {
+		return nil, fmt.Errorf("datasource %v/%v referenced by dashboard %v/%v is not imported in instance %v/%v", namespace, ref.Name, dashboard.Namespace, dashboard.Name, grafana.Namespace, grafana.Name)
+	}
+
+	// instances provisioned from files aren't queried, the type is taken from the datasource cr
+	if grafanaClient == nil {
+		datasource := &v1beta1.GrafanaDatasource{}
+		err := r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, datasource)
+		if err != nil {
+			return nil, err
+		}
+		if datasource.Spec.Datasource == nil {
+			return nil, fmt.Errorf("datasource %v/%v referenced by dashboard %v/%v is empty", namespace, ref.Name, dashboard.Namespace, dashboard.Name)
+		}
+
+		return &datasourceReference{
+			uid:            *uid,
+			datasourceType: datasource.Spec.Datasource.Type,
+		}, nil
+	}
+
+	datasource, err := grafanaClient.DataSourceByUID(*uid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &datasourceReference{
+		uid:            datasource.UID,
+		datasourceType: datasource.Type,
+	}, nil
+}
+
+// fetchCachedDashboardJson fetches the json of a dashboard or library panel. A content cache too large for the status
+// is read from and written to config maps controlled by the owner.
+func (r *GrafanaDashboardReconciler) fetchCachedDashboardJson(ctx context.Context, grafana *v1beta1.Grafana, dashboard *v1beta1.GrafanaDashboard, owner metav1.Object, gvk schema.GroupVersionKind) ([]byte, error) {
+	err := fetchers.LoadContentCache(ctx, r.Client, dashboard.Namespace, &dashboard.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboardJson, err := r.fetchDashboardJson(ctx, grafana, dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	err = fetchers.StoreContentCache(ctx, r.Client, owner, gvk, &dashboard.Status)
+	if err != nil {
+		return nil, err
+	}
+
 	return dashboardJson, nil
 }
 
 // fetchDashboardJson delegates obtaining the dashboard json definition to one of the known fetchers, for example
 // from embedded raw json or from a url
-func (r *GrafanaDashboardReconciler) fetchDashboardJson(dashboard *v1beta1.GrafanaDashboard) ([]byte, error) {
+func (r *GrafanaDashboardReconciler) fetchDashboardJson(ctx context.Context, grafana *v1beta1.Grafana, dashboard *v1beta1.GrafanaDashboard) ([]byte, error) {
 	sourceTypes := dashboard.GetSourceTypes()
 
 	if len(sourceTypes) == 0 {
@@ -397,38 +666,176 @@ func (r *GrafanaDashboardReconciler) fetchDashboardJson(dashboard *v1beta1.Grafa
 	case v1beta1.DashboardSourceTypeGzipJson:
 		return v1beta1.Gunzip([]byte(dashboard.Spec.GzipJson))
 	case v1beta1.DashboardSourceTypeUrl:
-		return fetchers.FetchDashboardFromUrl(dashboard)
+		return fetchers.FetchDashboardFromUrl(ctx, r.Client, dashboard)
 	case v1beta1.DashboardSourceTypeJsonnet:
-		return fetchers.FetchJsonnet(dashboard, embeds.GrafonnetEmbed)
+		env, err := r.getJsonnetEnvironment(ctx, grafana, dashboard)
+		if err != nil {
+			return nil, err
+		}
+		return fetchers.FetchJsonnet(dashboard, env)
+	case v1beta1.DashboardSourceTypeConfigMap:
+		return fetchers.FetchDashboardFromConfigMap(ctx, r.Client, dashboard)
+	case v1beta1.DashboardSourceTypeSecret:
+		return fetchers.FetchDashboardFromSecret(ctx, r.Client, dashboard)
+	case v1beta1.DashboardSourceTypeGrafanaCom:
+		return fetchers.FetchDashboardFromGrafanaCom(ctx, dashboard)
+	case v1beta1.DashboardSourceTypeJsonnetProject:
+		env, err := r.getJsonnetEnvironment(ctx, grafana, dashboard)
+		if err != nil {
+			return nil, err
+		}
+		return fetchers.FetchJsonnetProject(ctx, r.Client, dashboard, env)
 	default:
 		return nil, fmt.Errorf("unknown source type %v found in dashboard %v", sourceTypes[0], dashboard.Name)
 	}
 }
 
-func (r *GrafanaDashboardReconciler) UpdateStatus(ctx context.Context, cr *v1beta1.GrafanaDashboard) error {
-	cr.Status.Hash = cr.Hash()
-	return r.Client.Status().Update(ctx, cr)
+// getJsonnetEnvironment collects the libraries and variables used to evaluate a jsonnet dashboard for an instance
+func (r *GrafanaDashboardReconciler) getJsonnetEnvironment(ctx context.Context, grafana *v1beta1.Grafana, dashboard *v1beta1.GrafanaDashboard) (fetchers.JsonnetEnvironment, error) {
+	env := fetchers.JsonnetEnvironment{
+		Libsonnet: embeds.GrafonnetEmbed,
+	}
+
+	libraries, err := r.getJsonnetLibraries(ctx, grafana)
+	if err != nil {
+		return env, err
+	}
+	env.Libraries = libraries
+
+	// variables set in the dashboard take precedence over the context variables
+	extVars, err := fetchers.GetJsonnetContextVariables(dashboard, grafana)
+	if err != nil {
+		return env, err
+	}
+
+	userExtVars, err := r.resolveJsonnetVariables(ctx, dashboard.Namespace, dashboard.Spec.JsonnetExtVars)
+	if err != nil {
+		return env, err
+	}
+	env.ExtVars = append(extVars, userExtVars...)
+
+	env.TLAs, err = r.resolveJsonnetVariables(ctx, dashboard.Namespace, dashboard.Spec.JsonnetTLAs)
+	if err != nil {
+		return env, err
+	}
+
+	return env, nil
+}
+
+// resolveJsonnetVariables reads the values of jsonnet variables from literals, config maps or secrets
+func (r *GrafanaDashboardReconciler) resolveJsonnetVariables(ctx context.Context, namespace string, variables []v1beta1.JsonnetVariable) ([]fetchers.JsonnetVariable, error) {
+	resolved := make([]fetchers.JsonnetVariable, 0, len(variables))
+	for _, variable := range variables {
+		value := variable.Value
+
+		if variable.ValueFrom != nil {
+			switch {
+			case variable.ValueFrom.ConfigMapKeyRef != nil:
+				ref := variable.ValueFrom.ConfigMapKeyRef
+				configMap := &v1.ConfigMap{}
+				err := r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, configMap)
+				if err != nil {
+					return nil, err
+				}
+				val, ok := configMap.Data[ref.Key]
+				if !ok {
+					return nil, fmt.Errorf("key %v not found in config map %v/%v for jsonnet variable %v", ref.Key, namespace, ref.Name, variable.Name)
+				}
+				value = val
+			case variable.ValueFrom.SecretKeyRef != nil:
+				ref := variable.ValueFrom.SecretKeyRef
+				secret := &v1.Secret{}
+				err := r.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret)
+				if err != nil {
+					return nil, err
+				}
+				val, ok := secret.Data[ref.Key]
+				if !ok {
+					return nil, fmt.Errorf("key %v not found in secret %v/%v for jsonnet variable %v", ref.Key, namespace, ref.Name, variable.Name)
+				}
+				value = string(val)
+			}
+		}
+
+		resolved = append(resolved, fetchers.JsonnetVariable{
+			Name:  variable.Name,
+			Value: value,
+			Code:  variable.Code,
+		})
+	}
+	return resolved, nil
 }
 
-func (r *GrafanaDashboardReconciler) Exists(client *grapi.Client, cr *v1beta1.GrafanaDashboard) (bool, error) {
-	dashboards, err := client.Dashboards()
+// getJsonnetLibraries returns the config maps in the namespace of the instance that are selected by its jsonnet
+// library selector, ordered by name
+func (r *GrafanaDashboardReconciler) getJsonnetLibraries(ctx context.Context, grafana *v1beta1.Grafana) ([]v1.ConfigMap, error) {
+	if grafana.Spec.Jsonnet == nil || grafana.Spec.Jsonnet.LibraryLabelSelector == nil {
+		return nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(grafana.Spec.Jsonnet.LibraryLabelSelector)
 	if err != nil {
-		return false, err
+		return nil, fmt.Errorf("invalid jsonnet library selector in grafana %v/%v: %w", grafana.Namespace, grafana.Name, err)
 	}
-	for _, dashboard := range dashboards {
-		if dashboard.UID == string(cr.UID) {
-			return true, nil
+
+	list := &v1.ConfigMapList{}
+	opts := []client.ListOption{
+		client.InNamespace(grafana.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	}
+
+	err = r.Client.List(ctx, list, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	return list.Items, nil
+}
+
+func (r *GrafanaDashboardReconciler) ExistingId(client *grapi.Client, uid string) (*int64, error) {
+	dashboard, err := client.DashboardByUID(uid)
+	if err != nil {
+		if strings.Contains(err.Error(), "status: 404") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	id, ok := dashboard.Model["id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("dashboard %v has no id", uid)
+	}
+	result := int64(id)
+	return &result, nil
+}
+
+// getDashboardUidConflict returns namespace/name of another dashboard that uses the uid in the instance
+func getDashboardUidConflict(grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard, uid string) string {
+	for _, dashboard := range grafana.Status.Dashboards {
+		namespace, name, dashboardUid := dashboard.Split()
+		if dashboardUid == uid && (namespace != cr.Namespace || name != cr.Name) {
+			return fmt.Sprintf("%v/%v", namespace, name)
 		}
 	}
-	return false, nil
+	return ""
 }
 
-func (r *GrafanaDashboardReconciler) GetOrCreateFolder(client *grapi.Client, cr *v1beta1.GrafanaDashboard) (int64, error) {
+func (r *GrafanaDashboardReconciler) GetOrCreateFolder(client *grapi.Client, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard) (int64, error) {
+	if cr.Spec.FolderRef != nil {
+		if cr.Spec.FolderTitle != "" {
+			return 0, fmt.Errorf("folder and folderRef can't both be set in dashboard %v/%v", cr.Namespace, cr.Name)
+		}
+		return r.GetReferencedFolderID(client, grafana, cr)
+	}
+
 	if cr.Spec.FolderTitle == "" {
 		return 0, nil
 	}
 
-	folderID, err := r.GetFolderID(client, cr)
+	folderID, err := r.GetFolderID(client, grafana, cr)
 	if err != nil {
 		return 0, err
 	}
@@ -441,28 +848,51 @@ func (r *GrafanaDashboardReconciler) GetOrCreateFolder(client *grapi.Client, cr
 	if err != nil {
 		return 0, err
 	}
+	client2.GetInventory(grafana).Invalidate()
 	return resp.ID, nil
 }
 
 func (r *GrafanaDashboardReconciler) GetFolderID(client *grapi.Client,
+	grafana *v1beta1.Grafana,
 	cr *v1beta1.GrafanaDashboard,
 ) (int64, error) {
-	folders, err := client.Folders()
+	folders, err := client2.GetInventory(grafana).Folders(client)
 	if err != nil {
 		return 0, err
 	}
 
 	for _, folder := range folders {
 		if folder.Title == cr.Spec.FolderTitle {
-			return folder.ID, nil
+			return int64(folder.ID), nil
 		}
 		continue
 	}
 	return 0, nil
 }
 
-func (r *GrafanaDashboardReconciler) DeleteFolderIfEmpty(client *grapi.Client, folderID int64) (http.Response, error) {
-	dashboards, err := client.Dashboards()
+// GetReferencedFolderID returns the id of the folder created for the referenced GrafanaFolder in the instance, the
+// dashboard has to wait until the folder is ready
+func (r *GrafanaDashboardReconciler) GetReferencedFolderID(client *grapi.Client, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard) (int64, error) {
+	namespace := cr.GetFolderRefNamespace()
+	found, uid := grafana.Status.Folders.Find(namespace, cr.Spec.FolderRef.Name)
+	if !found {
+		return 0, fmt.Errorf("folder %v/%v referenced by dashboard %v/%v is not ready in instance %v/%v", namespace, cr.Spec.FolderRef.Name, cr.Namespace, cr.Name, grafana.Namespace, grafana.Name)
+	}
+
+	folder, err := client.FolderByUID(*uid)
+	if err != nil {
+		return 0, err
+	}
+	return folder.ID, nil
+}
+
+func (r *GrafanaDashboardReconciler) DeleteFolderIfEmpty(client *grapi.Client, grafana *v1beta1.Grafana, folderID int64) (http.Response, error) {
+	// the inventory may be outdated, a folder is only deleted if the instance doesn't return any dashboards in it
+	dashboards, err := client.FolderDashboardSearch(url.Values{
+		"type":      {"dash-db"},
+		"folderIds": {strconv.FormatInt(folderID, 10)},
+		"limit":     {"1"},
+	})
 	if err != nil {
 		return http.Response{
 			Status:     "internal grafana client error getting dashboards",
@@ -470,14 +900,11 @@ func (r *GrafanaDashboardReconciler) DeleteFolderIfEmpty(client *grapi.Client, f
 		}, err
 	}
 
-	for _, dashboard := range dashboards {
-		if int64(dashboard.FolderID) == folderID {
-			return http.Response{
-				Status:     "resource is still in use",
-				StatusCode: 423, // Locked return code
-			}, err
-		}
-		continue
+	if len(dashboards) > 0 {
+		return http.Response{
+			Status:     "resource is still in use",
+			StatusCode: 423, // Locked return code
+		}, err
 	}
 
 	folder, err := client.Folder(folderID)
@@ -488,12 +915,21 @@ func (r *GrafanaDashboardReconciler) DeleteFolderIfEmpty(client *grapi.Client, f
 		}, err
 	}
 
+	// folders of GrafanaFolders are removed together with the GrafanaFolder
+	if grafana.Status.Folders.ContainsUid(folder.UID) {
+		return http.Response{
+			Status:     "folder is managed by a GrafanaFolder",
+			StatusCode: 423,
+		}, nil
+	}
+
 	if err = client.DeleteFolder(folder.UID); err != nil {
 		return http.Response{
 			Status:     "internal grafana client error deleting grafana folder",
 			StatusCode: 500,
 		}, err
 	}
+	client2.GetInventory(grafana).Invalidate()
 	return http.Response{
 		Status:     "grafana folder deleted",
 		StatusCode: 200,
@@ -502,8 +938,37 @@ func (r *GrafanaDashboardReconciler) DeleteFolderIfEmpty(client *grapi.Client, f
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *GrafanaDashboardReconciler) SetupWithManager(mgr ctrl.Manager, ctx context.Context) error {
-	err := ctrl.NewControllerManagedBy(mgr).
+	err := mgr.GetFieldIndexer().IndexField(ctx, &v1beta1.GrafanaDashboard{}, dashboardConfigMapIndexKey, func(o client.Object) []string {
+		return getReferencedConfigMaps(o.(*v1beta1.GrafanaDashboard))
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(ctx, &v1beta1.GrafanaDashboard{}, dashboardSecretIndexKey, func(o client.Object) []string {
+		return getReferencedSecrets(o.(*v1beta1.GrafanaDashboard))
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(ctx, &v1beta1.GrafanaDashboard{}, dashboardFolderIndexKey, func(o client.Object) []string {
+		dashboard := o.(*v1beta1.GrafanaDashboard)
+		if dashboard.Spec.FolderRef == nil {
+			return nil
+		}
+		return []string{fmt.Sprintf("%v/%v", dashboard.GetFolderRefNamespace(), dashboard.Spec.FolderRef.Name)}
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.GrafanaDashboard{}).
+		Watches(&source.Kind{Type: &v1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.requestsForReferencingDashboards(dashboardConfigMapIndexKey))).
+		Watches(&source.Kind{Type: &v1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.requestsForReferencingDashboards(dashboardSecretIndexKey))).
+		Watches(&source.Kind{Type: &v1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.requestsForJsonnetLibrary)).
+		Watches(&source.Kind{Type: &v1beta1.GrafanaFolder{}}, handler.EnqueueRequestsFromMapFunc(r.requestsForFolder)).
 		Complete(r)
 
 	if err == nil {
@@ -537,8 +1002,173 @@ func (r *GrafanaDashboardReconciler) SetupWithManager(mgr ctrl.Manager, ctx cont
 	return err
 }
 
+// getReferencedConfigMaps returns the names of all config maps the dashboard reads its content from
+func getReferencedConfigMaps(dashboard *v1beta1.GrafanaDashboard) []string {
+	var names []string
+	if dashboard.Spec.ConfigMapRef != nil {
+		names = append(names, dashboard.Spec.ConfigMapRef.Name)
+	}
+
+	if dashboard.Spec.JsonnetProject != nil && dashboard.Spec.JsonnetProject.ConfigMapRef != nil {
+		names = append(names, dashboard.Spec.JsonnetProject.ConfigMapRef.Name)
+	}
+
+	if dashboard.Spec.UrlCaBundle != nil && dashboard.Spec.UrlCaBundle.ConfigMapKeyRef != nil {
+		names = append(names, dashboard.Spec.UrlCaBundle.ConfigMapKeyRef.Name)
+	}
+
+	for _, variable := range append(dashboard.Spec.JsonnetExtVars, dashboard.Spec.JsonnetTLAs...) {
+		if variable.ValueFrom != nil && variable.ValueFrom.ConfigMapKeyRef != nil {
+			names = append(names, variable.ValueFrom.ConfigMapKeyRef.Name)
+		}
+	}
+	return names
+}
+
+// getReferencedSecrets returns the names of all secrets the dashboard reads its content from
+func getReferencedSecrets(dashboard *v1beta1.GrafanaDashboard) []string {
+	var names []string
+	if dashboard.Spec.SecretRef != nil {
+		names = append(names, dashboard.Spec.SecretRef.Name)
+	}
+
+	if auth := dashboard.Spec.UrlAuthorization; auth != nil {
+		refs := []*v1.SecretKeySelector{auth.BearerToken}
+		if auth.BasicAuth != nil {
+			refs = append(refs, auth.BasicAuth.Username, auth.BasicAuth.Password)
+		}
+		for _, header := range auth.Headers {
+			refs = append(refs, header.ValueFrom)
+		}
+		for _, ref := range refs {
+			if ref != nil {
+				names = append(names, ref.Name)
+			}
+		}
+	}
+
+	if dashboard.Spec.UrlCaBundle != nil && dashboard.Spec.UrlCaBundle.SecretKeyRef != nil {
+		names = append(names, dashboard.Spec.UrlCaBundle.SecretKeyRef.Name)
+	}
+
+	for _, variable := range append(dashboard.Spec.JsonnetExtVars, dashboard.Spec.JsonnetTLAs...) {
+		if variable.ValueFrom != nil && variable.ValueFrom.SecretKeyRef != nil {
+			names = append(names, variable.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	return names
+}
+
+// requestsForReferencingDashboards returns a map function that enqueues all dashboards referencing the changed
+// object through the given field index, so that the dashboards get re-imported when the content changes
+func (r *GrafanaDashboardReconciler) requestsForReferencingDashboards(indexKey string) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		list := &v1beta1.GrafanaDashboardList{}
+		opts := []client.ListOption{
+			client.InNamespace(o.GetNamespace()),
+			client.MatchingFields{indexKey: o.GetName()},
+		}
+
+		err := r.Client.List(context.Background(), list, opts...)
+		if err != nil {
+			r.Log.Error(err, "error listing dashboards referencing object", "namespace", o.GetNamespace(), "name", o.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(list.Items))
+		for _, dashboard := range list.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: dashboard.Namespace,
+				Name:      dashboard.Name,
+			}})
+		}
+		return requests
+	}
+}
+
+// requestsForFolder enqueues the dashboards referencing the changed folder, so that they don't have to wait for their
+// next retry once the folder is ready
+func (r *GrafanaDashboardReconciler) requestsForFolder(o client.Object) []reconcile.Request {
+	list := &v1beta1.GrafanaDashboardList{}
+	opts := []client.ListOption{
+		client.MatchingFields{dashboardFolderIndexKey: fmt.Sprintf("%v/%v", o.GetNamespace(), o.GetName())},
+	}
+
+	err := r.Client.List(context.Background(), list, opts...)
+	if err != nil {
+		r.Log.Error(err, "error listing dashboards referencing folder", "namespace", o.GetNamespace(), "name", o.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, dashboard := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: dashboard.Namespace,
+			Name:      dashboard.Name,
+		}})
+	}
+	return requests
+}
+
+// requestsForJsonnetLibrary enqueues the jsonnet dashboards of all instances that select the changed config map
+// as a jsonnet library
+func (r *GrafanaDashboardReconciler) requestsForJsonnetLibrary(o client.Object) []reconcile.Request {
+	ctx := context.Background()
+
+	grafanas := &v1beta1.GrafanaList{}
+	err := r.Client.List(ctx, grafanas, client.InNamespace(o.GetNamespace()))
+	if err != nil {
+		r.Log.Error(err, "error listing grafana instances for jsonnet library", "namespace", o.GetNamespace(), "name", o.GetName())
+		return nil
+	}
+
+	var instances []v1beta1.Grafana
+	for _, grafana := range grafanas.Items {
+		if grafana.Spec.Jsonnet == nil || grafana.Spec.Jsonnet.LibraryLabelSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(grafana.Spec.Jsonnet.LibraryLabelSelector)
+		if err != nil || !selector.Matches(labels.Set(o.GetLabels())) {
+			continue
+		}
+		instances = append(instances, grafana)
+	}
+
+	if len(instances) == 0 {
+		return nil
+	}
+
+	dashboards := &v1beta1.GrafanaDashboardList{}
+	err = r.Client.List(ctx, dashboards)
+	if err != nil {
+		r.Log.Error(err, "error listing dashboards for jsonnet library", "namespace", o.GetNamespace(), "name", o.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, dashboard := range dashboards.Items {
+		if dashboard.Spec.Jsonnet == "" && dashboard.Spec.JsonnetProject == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(dashboard.Spec.InstanceSelector)
+		if err != nil {
+			continue
+		}
+		for _, grafana := range instances {
+			if selector.Matches(labels.Set(grafana.Labels)) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: dashboard.Namespace,
+					Name:      dashboard.Name,
+				}})
+				break
+			}
+		}
+	}
+	return requests
+}
+
 func (r *GrafanaDashboardReconciler) GetMatchingDashboardInstances(ctx context.Context, dashboard *v1beta1.GrafanaDashboard, k8sClient client.Client) (v1beta1.GrafanaList, error) {
-	instances, err := GetMatchingInstances(ctx, k8sClient, dashboard.Spec.InstanceSelector)
+	instances, err := GetMatchingInstances(ctx, k8sClient, dashboard.Spec.InstanceSelector, dashboard.Spec.InstanceNamespaceSelector)
 	if err != nil || len(instances.Items) == 0 {
 		dashboard.Status.NoMatchingInstances = true
 		if err := r.Client.Status().Update(ctx, dashboard); err != nil {
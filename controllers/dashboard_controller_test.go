@@ -17,6 +17,8 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
@@ -117,3 +119,44 @@ func TestGetDashboardsToDelete(t *testing.T) {
 		}
 	}
 }
+
+func TestGetDashboardUidConflict(t *testing.T) {
+	grafana := &v1beta1.Grafana{
+		Status: v1beta1.GrafanaStatus{
+			Dashboards: v1beta1.NamespacedResourceList{
+				"team-a/overview/overview",
+				"team-b/nodes/nodes",
+			},
+		},
+	}
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "overview",
+			Namespace: "team-a",
+		},
+	}
+
+	assert.Equal(t, "", getDashboardUidConflict(grafana, dashboard, "overview"))
+	assert.Equal(t, "", getDashboardUidConflict(grafana, dashboard, "new-uid"))
+	assert.Equal(t, "team-b/nodes", getDashboardUidConflict(grafana, dashboard, "nodes"))
+}
+
+func TestOnDashboardProvisioned_RejectsFoldersAndPermissions(t *testing.T) {
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"},
+		Spec:       v1beta1.GrafanaSpec{ProvisioningMode: v1beta1.ProvisioningModeFile},
+	}
+	r := &GrafanaDashboardReconciler{}
+
+	for _, spec := range []v1beta1.GrafanaDashboardSpec{
+		{Json: "{}", FolderTitle: "team-a"},
+		{Json: "{}", FolderRef: &v1beta1.GrafanaFolderReference{Name: "team-a"}},
+		{Json: "{}", Permissions: []v1beta1.Permission{{Role: "Viewer", Permission: v1beta1.PermissionLevelView}}},
+	} {
+		dashboard := &v1beta1.GrafanaDashboard{ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "monitoring"}, Spec: spec}
+		_, err := r.onDashboardProvisioned(context.Background(), grafana, dashboard)
+
+		var unsupported unsupportedError
+		assert.True(t, errors.As(err, &unsupported), err)
+	}
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// datasourceReference is the uid and type of a datasource in a grafana instance
+type datasourceReference struct {
+	uid            string
+	datasourceType string
+}
+
+// replaceDatasourceReferences replaces the datasource references of panels, targets, annotations and template
+// variables that point at an ${inputName} placeholder with the uid and type of the datasource resolved for the input.
+// Legacy references by name are converted to uid references.
+func replaceDatasourceReferences(dashboardJson []byte, inputs map[string]datasourceReference) ([]byte, error) {
+	if len(inputs) == 0 {
+		return dashboardJson, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(dashboardJson))
+	decoder.UseNumber()
+
+	var dashboard interface{}
+	err := decoder.Decode(&dashboard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dashboard json: %v", err)
+	}
+
+	return json.Marshal(replaceDatasourceReferencesIn(dashboard, inputs))
+}
+
+func replaceDatasourceReferencesIn(value interface{}, inputs map[string]datasourceReference) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if key == "datasource" {
+				v[key] = replaceDatasourceReference(field, inputs)
+				continue
+			}
+			v[key] = replaceDatasourceReferencesIn(field, inputs)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = replaceDatasourceReferencesIn(item, inputs)
+		}
+	}
+	return value
+}
+
+func replaceDatasourceReference(value interface{}, inputs map[string]datasourceReference) interface{} {
+	switch v := value.(type) {
+	case string:
+		if ref, ok := inputs[v]; ok {
+			return map[string]interface{}{
+				"type": ref.datasourceType,
+				"uid":  ref.uid,
+			}
+		}
+	case map[string]interface{}:
+		if uid, ok := v["uid"].(string); ok {
+			if ref, ok := inputs[uid]; ok {
+				v["uid"] = ref.uid
+				v["type"] = ref.datasourceType
+			}
+		}
+	}
+	return value
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceDatasourceReferences(t *testing.T) {
+	dashboardJson := []byte(`{
+  "id": 12345678901234567890,
+  "annotations": {"list": [{"datasource": "${DS_PROMETHEUS}"}]},
+  "panels": [
+    {
+      "datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"},
+      "targets": [
+        {"datasource": {"type": "prometheus", "uid": "${DS_PROMETHEUS}"}, "expr": "up"},
+        {"datasource": {"type": "loki", "uid": "${DS_LOKI}"}}
+      ]
+    },
+    {"datasource": "-- Mixed --", "title": "${DS_PROMETHEUS}"}
+  ],
+  "templating": {"list": [{"datasource": {"uid": "${DS_PROMETHEUS}"}, "name": "job"}]}
+}`)
+
+	expected := `{
+  "id": 12345678901234567890,
+  "annotations": {"list": [{"datasource": {"type": "prometheus", "uid": "abc"}}]},
+  "panels": [
+    {
+      "datasource": {"type": "prometheus", "uid": "abc"},
+      "targets": [
+        {"datasource": {"type": "prometheus", "uid": "abc"}, "expr": "up"},
+        {"datasource": {"type": "loki", "uid": "${DS_LOKI}"}}
+      ]
+    },
+    {"datasource": "-- Mixed --", "title": "${DS_PROMETHEUS}"}
+  ],
+  "templating": {"list": [{"datasource": {"type": "prometheus", "uid": "abc"}, "name": "job"}]}
+}`
+
+	result, err := replaceDatasourceReferences(dashboardJson, map[string]datasourceReference{
+		"${DS_PROMETHEUS}": {uid: "abc", datasourceType: "prometheus"},
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, expected, string(result))
+
+	_, err = replaceDatasourceReferences([]byte(`{`), map[string]datasourceReference{
+		"${DS_PROMETHEUS}": {uid: "abc", datasourceType: "prometheus"},
+	})
+	assert.Error(t, err)
+}
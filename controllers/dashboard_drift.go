@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	grapi "github.com/grafana/grafana-api-golang-client"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fields Grafana sets whenever a dashboard is saved, they never count as drift
+var dashboardServerFields = map[string]bool{
+	"id":      true,
+	"uid":     true,
+	"version": true,
+}
+
+// reconcileDrift compares the dashboard in the instance with the dashboard of the CR and applies the drift policy.
+// Returns true if the dashboard has to be pushed again to revert the drift. Drift records are written with the status
+// of the dashboard at the end of the reconcile.
+func (r *GrafanaDashboardReconciler) reconcileDrift(client *grapi.Client, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDashboard, uid string, dashboardJson []byte) (bool, error) {
+	instance := fmt.Sprintf("%v/%v", grafana.Namespace, grafana.Name)
+
+	if cr.GetDriftPolicy() == v1beta1.DriftPolicyIgnore {
+		setDashboardDrift(cr, instance, nil)
+		return false, nil
+	}
+
+	live, err := client.DashboardByUID(uid)
+	if err != nil {
+		return false, err
+	}
+
+	var desired map[string]interface{}
+	err = json.Unmarshal(dashboardJson, &desired)
+	if err != nil {
+		return false, err
+	}
+
+	changedFields := getChangedDashboardFields(desired, live.Model)
+	if len(changedFields) == 0 {
+		setDashboardDrift(cr, instance, nil)
+		return false, nil
+	}
+
+	var version int64
+	if v, ok := live.Model["version"].(float64); ok {
+		version = int64(v)
+	}
+
+	if cr.GetDriftPolicy() == v1beta1.DriftPolicyRevert {
+		r.Recorder.Eventf(cr, v1.EventTypeNormal, "DriftReverted", "reverted changes to %v of version %v in instance %v", strings.Join(changedFields, ", "), version, instance)
+		// the drift record is removed when the status is updated after the dashboard was pushed
+		setDashboardDrift(cr, instance, nil)
+		return true, nil
+	}
+
+	drift := &v1beta1.GrafanaDashboardDrift{
+		Instance:      instance,
+		Version:       version,
+		ChangedFields: changedFields,
+		DetectedAt:    metav1.Now(),
+	}
+	if setDashboardDrift(cr, instance, drift) {
+		r.Recorder.Eventf(cr, v1.EventTypeWarning, "DriftDetected", "dashboard was changed in instance %v, changed fields: %v", instance, strings.Join(changedFields, ", "))
+	}
+	return false, nil
+}
+
+// getChangedDashboardFields returns the sorted top level fields that differ between the desired and the live dashboard
+func getChangedDashboardFields(desired, live map[string]interface{}) []string {
+	fields := map[string]bool{}
+	for key := range desired {
+		fields[key] = true
+	}
+	for key := range live {
+		fields[key] = true
+	}
+
+	var changed []string
+	for field := range fields {
+		if dashboardServerFields[field] {
+			continue
+		}
+		if !reflect.DeepEqual(desired[field], live[field]) {
+			changed = append(changed, field)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// setDashboardDrift records or, if drift is nil, removes the drift of an instance in the status. The detection time
+// of a known drift is kept. Returns true if the status changed.
+func setDashboardDrift(cr *v1beta1.GrafanaDashboard, instance string, drift *v1beta1.GrafanaDashboardDrift) bool {
+	var drifts []v1beta1.GrafanaDashboardDrift
+	var existing *v1beta1.GrafanaDashboardDrift
+	for i, d := range cr.Status.Drift {
+		if d.Instance == instance {
+			existing = &cr.Status.Drift[i]
+			continue
+		}
+		drifts = append(drifts, d)
+	}
+
+	if drift == nil {
+		cr.Status.Drift = drifts
+		return existing != nil
+	}
+
+	if existing != nil {
+		if existing.Version == drift.Version && reflect.DeepEqual(existing.ChangedFields, drift.ChangedFields) {
+			return false
+		}
+		drift.DetectedAt = existing.DetectedAt
+	}
+
+	cr.Status.Drift = append(drifts, *drift)
+	return true
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetChangedDashboardFields(t *testing.T) {
+	desired := map[string]interface{}{
+		"title":  "dashboard",
+		"panels": []interface{}{map[string]interface{}{"type": "text"}},
+		"tags":   []interface{}{"a"},
+	}
+	live := map[string]interface{}{
+		"id":      float64(12),
+		"uid":     "abc",
+		"version": float64(3),
+		"title":   "dashboard",
+		"panels":  []interface{}{map[string]interface{}{"type": "graph"}},
+		"refresh": "5s",
+	}
+
+	assert.Equal(t, []string{"panels", "refresh", "tags"}, getChangedDashboardFields(desired, live))
+	assert.Empty(t, getChangedDashboardFields(desired, map[string]interface{}{
+		"id":     float64(12),
+		"title":  "dashboard",
+		"panels": []interface{}{map[string]interface{}{"type": "text"}},
+		"tags":   []interface{}{"a"},
+	}))
+}
+
+func TestSetDashboardDrift(t *testing.T) {
+	detectedAt := metav1.Time{Time: time.Now().Add(-time.Hour)}
+	cr := &v1beta1.GrafanaDashboard{
+		Status: v1beta1.GrafanaDashboardStatus{
+			Drift: []v1beta1.GrafanaDashboardDrift{
+				{Instance: "grafana/a", Version: 2, ChangedFields: []string{"panels"}, DetectedAt: detectedAt},
+			},
+		},
+	}
+
+	// known drift doesn't change the status
+	assert.False(t, setDashboardDrift(cr, "grafana/a", &v1beta1.GrafanaDashboardDrift{
+		Instance: "grafana/a", Version: 2, ChangedFields: []string{"panels"}, DetectedAt: metav1.Now(),
+	}))
+
+	// new versions keep the time the drift was first detected
+	assert.True(t, setDashboardDrift(cr, "grafana/a", &v1beta1.GrafanaDashboardDrift{
+		Instance: "grafana/a", Version: 3, ChangedFields: []string{"panels", "title"}, DetectedAt: metav1.Now(),
+	}))
+	assert.Equal(t, int64(3), cr.Status.Drift[0].Version)
+	assert.Equal(t, detectedAt, cr.Status.Drift[0].DetectedAt)
+
+	assert.True(t, setDashboardDrift(cr, "grafana/b", &v1beta1.GrafanaDashboardDrift{Instance: "grafana/b", Version: 1}))
+	assert.Len(t, cr.Status.Drift, 2)
+
+	assert.True(t, setDashboardDrift(cr, "grafana/a", nil))
+	assert.False(t, setDashboardDrift(cr, "grafana/a", nil))
+	assert.Equal(t, "grafana/b", cr.Status.Drift[0].Instance)
+}
@@ -0,0 +1,305 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	grapi "github.com/grafana/grafana-api-golang-client"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// datasource references to Grafana's built in datasources, they are exported as is
+var builtinDatasources = map[string]bool{
+	"grafana":         true,
+	"-- Grafana --":   true,
+	"-- Mixed --":     true,
+	"-- Dashboard --": true,
+}
+
+var invalidNameCharacters = regexp.MustCompile("[^a-z0-9]+")
+
+var invalidInputCharacters = regexp.MustCompile("[^A-Z0-9]+")
+
+// ExportConfigMapSize is the maximum size in bytes of the manifests written to a single config map, the size of a
+// config map is limited to 1MiB
+var ExportConfigMapSize = 512 * 1024
+
+// dashboardExporter templates the dashboards of an instance into GrafanaDashboard manifests
+type dashboardExporter struct {
+	export      *v1beta1.GrafanaDashboardExport
+	grafana     *v1beta1.Grafana
+	datasources []*grapi.DataSource
+	// names of the exported dashboards, unique across all instances of an export
+	names map[string]bool
+}
+
+// exportDashboard returns the name and the yaml manifest of a GrafanaDashboard for a dashboard found in the instance
+func (e *dashboardExporter) exportDashboard(search grapi.FolderDashboardSearchResponse, model map[string]interface{}) (string, []byte, error) {
+	// set by Grafana whenever the dashboard is saved
+	delete(model, "id")
+	delete(model, "version")
+
+	spec := v1beta1.GrafanaDashboardSpec{
+		InstanceSelector: e.export.GetDashboardInstanceSelector(),
+		Datasources:      e.templateDatasources(model),
+	}
+
+	if search.FolderUID != "" {
+		spec.FolderRef = e.getFolderReference(search.FolderUID)
+		if spec.FolderRef == nil {
+			spec.FolderTitle = search.FolderTitle
+		}
+	}
+
+	dashboardJson, err := json.MarshalIndent(model, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode dashboard %v: %v", search.UID, err)
+	}
+	spec.Json = string(dashboardJson)
+
+	dashboard := v1beta1.GrafanaDashboard{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1beta1.GroupVersion.String(),
+			Kind:       "GrafanaDashboard",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      e.getDashboardName(search),
+			Namespace: e.export.Namespace,
+		},
+		Spec: spec,
+	}
+
+	manifest, err := toManifest(dashboard)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode dashboard %v: %v", search.UID, err)
+	}
+
+	return dashboard.Name, manifest, nil
+}
+
+// templateDatasources replaces the datasource references in the dashboard with ${DS_<NAME>} inputs and returns the
+// datasource rules resolving the inputs
+func (e *dashboardExporter) templateDatasources(model map[string]interface{}) []v1beta1.GrafanaDashboardDatasource {
+	var datasources []v1beta1.GrafanaDashboardDatasource
+	inputs := map[string]string{}
+	inputNames := map[string]bool{}
+
+	getInput := func(nameOrUid string) (string, bool) {
+		var datasource *grapi.DataSource
+		for _, ds := range e.datasources {
+			if ds.UID == nameOrUid || ds.Name == nameOrUid {
+				datasource = ds
+				break
+			}
+		}
+		if datasource == nil {
+			return "", false
+		}
+
+		if input, ok := inputs[datasource.UID]; ok {
+			return input, true
+		}
+
+		input := getDatasourceInputName(datasource.Name, inputNames)
+		inputs[datasource.UID] = input
+		inputNames[input] = true
+		datasources = append(datasources, e.getDatasourceRule(input, datasource))
+		return input, true
+	}
+
+	templateDatasourceReferencesIn(model, getInput)
+	return datasources
+}
+
+// getDatasourceRule references the GrafanaDatasource if the datasource is managed by the operator and the
+// datasource name otherwise
+func (e *dashboardExporter) getDatasourceRule(input string, datasource *grapi.DataSource) v1beta1.GrafanaDashboardDatasource {
+	rule := v1beta1.GrafanaDashboardDatasource{
+		InputName: input,
+	}
+
+	for _, managed := range e.grafana.Status.Datasources {
+		namespace, name, uid := managed.Split()
+		if uid != datasource.UID {
+			continue
+		}
+
+		rule.DatasourceRef = &v1beta1.GrafanaDatasourceReference{
+			Name: name,
+		}
+		if namespace != e.export.Namespace {
+			rule.DatasourceRef.Namespace = namespace
+		}
+		return rule
+	}
+
+	rule.DatasourceName = datasource.Name
+	return rule
+}
+
+// getFolderReference references the GrafanaFolder if the folder is managed by the operator
+func (e *dashboardExporter) getFolderReference(uid string) *v1beta1.GrafanaFolderReference {
+	for _, managed := range e.grafana.Status.Folders {
+		namespace, name, folderUid := managed.Split()
+		if folderUid != uid {
+			continue
+		}
+
+		ref := &v1beta1.GrafanaFolderReference{
+			Name: name,
+		}
+		if namespace != e.export.Namespace {
+			ref.Namespace = namespace
+		}
+		return ref
+	}
+	return nil
+}
+
+// getDashboardName derives a unique resource name from the title of the dashboard
+func (e *dashboardExporter) getDashboardName(search grapi.FolderDashboardSearchResponse) string {
+	name := strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(search.Title), "-"), "-")
+	if len(name) > 200 {
+		name = strings.Trim(name[:200], "-")
+	}
+
+	uid := strings.Trim(invalidNameCharacters.ReplaceAllString(strings.ToLower(search.UID), "-"), "-")
+	if name == "" {
+		name = fmt.Sprintf("dashboard-%v", uid)
+	}
+	if e.names[name] {
+		name = fmt.Sprintf("%v-%v", name, uid)
+	}
+
+	e.names[name] = true
+	return name
+}
+
+// getDatasourceInputName returns a DS_<NAME> input name for the datasource that is not in use yet
+func getDatasourceInputName(datasourceName string, used map[string]bool) string {
+	name := strings.Trim(invalidInputCharacters.ReplaceAllString(strings.ToUpper(datasourceName), "_"), "_")
+	input := fmt.Sprintf("DS_%v", name)
+	for i := 2; used[input]; i++ {
+		input = fmt.Sprintf("DS_%v_%v", name, i)
+	}
+	return input
+}
+
+func templateDatasourceReferencesIn(value interface{}, getInput func(string) (string, bool)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if key == "datasource" {
+				v[key] = templateDatasourceReference(field, getInput)
+				continue
+			}
+			templateDatasourceReferencesIn(field, getInput)
+		}
+	case []interface{}:
+		for _, item := range v {
+			templateDatasourceReferencesIn(item, getInput)
+		}
+	}
+}
+
+func templateDatasourceReference(value interface{}, getInput func(string) (string, bool)) interface{} {
+	// references to template variables and built in datasources stay as they are
+	isTemplatable := func(ref string) bool {
+		return ref != "" && !strings.HasPrefix(ref, "$") && !builtinDatasources[ref]
+	}
+
+	switch v := value.(type) {
+	case string:
+		if !isTemplatable(v) {
+			return v
+		}
+		if input, ok := getInput(v); ok {
+			return fmt.Sprintf("${%v}", input)
+		}
+	case map[string]interface{}:
+		uid, ok := v["uid"].(string)
+		if !ok || !isTemplatable(uid) {
+			return v
+		}
+		if input, ok := getInput(uid); ok {
+			v["uid"] = fmt.Sprintf("${%v}", input)
+		}
+	}
+	return value
+}
+
+// toManifest encodes an object as yaml without the empty status and server populated metadata
+func toManifest(obj interface{}) ([]byte, error) {
+	bytes, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest map[string]interface{}
+	err = json.Unmarshal(bytes, &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(manifest, "status")
+	if metadata, ok := manifest["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "creationTimestamp")
+	}
+
+	return yaml.Marshal(manifest)
+}
+
+// splitExportManifests distributes the manifests over as many config maps as needed to keep each below
+// ExportConfigMapSize. Manifests are added in the order of their keys, a manifest is never split. There is always at
+// least one config map, so an export without dashboards still writes an empty one.
+func splitExportManifests(manifests map[string]string) []map[string]string {
+	keys := make([]string, 0, len(manifests))
+	for key := range manifests {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	shards := []map[string]string{{}}
+	size := 0
+	for _, key := range keys {
+		entrySize := len(key) + len(manifests[key])
+		if size > 0 && size+entrySize > ExportConfigMapSize {
+			shards = append(shards, map[string]string{})
+			size = 0
+		}
+		shards[len(shards)-1][key] = manifests[key]
+		size += entrySize
+	}
+	return shards
+}
+
+// getExportConfigMapName returns the name of the i-th config map of an export, the first one is named after the
+// export and the following ones get a numeric suffix
+func getExportConfigMapName(export *v1beta1.GrafanaDashboardExport, i int) string {
+	if i == 0 {
+		return export.GetConfigMapName()
+	}
+	return fmt.Sprintf("%v-%d", export.GetConfigMapName(), i)
+}
+
+// hashExportManifests returns a digest of the exported manifests, the export time is only updated when it changes
+func hashExportManifests(manifests map[string]string) string {
+	keys := make([]string, 0, len(manifests))
+	for key := range manifests {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte(manifests[key]))
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	grapi "github.com/grafana/grafana-api-golang-client"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestExportDashboard(t *testing.T) {
+	export := &v1beta1.GrafanaDashboardExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "export",
+			Namespace: "monitoring",
+		},
+		Spec: v1beta1.GrafanaDashboardExportSpec{
+			InstanceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"dashboards": "grafana"},
+			},
+		},
+	}
+	grafana := &v1beta1.Grafana{
+		Status: v1beta1.GrafanaStatus{
+			Datasources: v1beta1.NamespacedResourceList{"monitoring/prometheus/prom-uid"},
+			Folders:     v1beta1.NamespacedResourceList{"teams/team-a/folder-a"},
+		},
+	}
+	exporter := &dashboardExporter{
+		export:  export,
+		grafana: grafana,
+		datasources: []*grapi.DataSource{
+			{UID: "prom-uid", Name: "Prometheus", Type: "prometheus"},
+			{UID: "loki-uid", Name: "Loki logs", Type: "loki"},
+		},
+		names: map[string]bool{},
+	}
+
+	model := map[string]interface{}{
+		"id":      float64(3),
+		"uid":     "ui-made",
+		"version": float64(7),
+		"title":   "Team A: Overview",
+		"panels": []interface{}{
+			map[string]interface{}{"datasource": map[string]interface{}{"type": "prometheus", "uid": "prom-uid"}},
+			map[string]interface{}{"datasource": "Loki logs"},
+			map[string]interface{}{"datasource": map[string]interface{}{"type": "datasource", "uid": "-- Mixed --"}},
+			map[string]interface{}{"datasource": "${datasource}"},
+			map[string]interface{}{"datasource": map[string]interface{}{"type": "tempo", "uid": "unknown"}},
+		},
+	}
+
+	name, manifest, err := exporter.exportDashboard(grapi.FolderDashboardSearchResponse{
+		UID:         "ui-made",
+		Title:       "Team A: Overview",
+		FolderUID:   "folder-a",
+		FolderTitle: "Team A",
+	}, model)
+	assert.Nil(t, err)
+	assert.Equal(t, "team-a-overview", name)
+
+	var dashboard v1beta1.GrafanaDashboard
+	assert.Nil(t, yaml.Unmarshal(manifest, &dashboard))
+	assert.Equal(t, "GrafanaDashboard", dashboard.Kind)
+	assert.Equal(t, "monitoring", dashboard.Namespace)
+	assert.Equal(t, export.Spec.InstanceSelector, dashboard.Spec.InstanceSelector)
+	assert.Equal(t, &v1beta1.GrafanaFolderReference{Name: "team-a", Namespace: "teams"}, dashboard.Spec.FolderRef)
+	assert.Equal(t, "", dashboard.Spec.FolderTitle)
+	assert.Equal(t, []v1beta1.GrafanaDashboardDatasource{
+		{InputName: "DS_PROMETHEUS", DatasourceRef: &v1beta1.GrafanaDatasourceReference{Name: "prometheus"}},
+		{InputName: "DS_LOKI_LOGS", DatasourceName: "Loki logs"},
+	}, dashboard.Spec.Datasources)
+
+	var exported map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(dashboard.Spec.Json), &exported))
+	assert.NotContains(t, exported, "id")
+	assert.NotContains(t, exported, "version")
+	assert.Equal(t, "ui-made", exported["uid"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"datasource": map[string]interface{}{"type": "prometheus", "uid": "${DS_PROMETHEUS}"}},
+		map[string]interface{}{"datasource": "${DS_LOKI_LOGS}"},
+		map[string]interface{}{"datasource": map[string]interface{}{"type": "datasource", "uid": "-- Mixed --"}},
+		map[string]interface{}{"datasource": "${datasource}"},
+		map[string]interface{}{"datasource": map[string]interface{}{"type": "tempo", "uid": "unknown"}},
+	}, exported["panels"])
+
+	// a second dashboard with the same title in an unmanaged folder
+	name, manifest, err = exporter.exportDashboard(grapi.FolderDashboardSearchResponse{
+		UID:         "Other_UID",
+		Title:       "Team A: Overview",
+		FolderUID:   "folder-b",
+		FolderTitle: "Team B",
+	}, map[string]interface{}{"title": "Team A: Overview"})
+	assert.Nil(t, err)
+	assert.Equal(t, "team-a-overview-other-uid", name)
+
+	dashboard = v1beta1.GrafanaDashboard{}
+	assert.Nil(t, yaml.Unmarshal(manifest, &dashboard))
+	assert.Nil(t, dashboard.Spec.FolderRef)
+	assert.Equal(t, "Team B", dashboard.Spec.FolderTitle)
+	assert.Empty(t, dashboard.Spec.Datasources)
+}
+
+func TestGetDatasourceInputName(t *testing.T) {
+	used := map[string]bool{"DS_PROMETHEUS": true, "DS_PROMETHEUS_2": true}
+	assert.Equal(t, "DS_PROMETHEUS_3", getDatasourceInputName("prometheus", used))
+	assert.Equal(t, "DS_MY_LOKI_EU", getDatasourceInputName("my-loki (eu)", used))
+}
+
+func TestSplitExportManifests(t *testing.T) {
+	previous := ExportConfigMapSize
+	defer func() { ExportConfigMapSize = previous }()
+	ExportConfigMapSize = 20
+
+	shards := splitExportManifests(map[string]string{
+		"a.yaml": "0123456789",
+		"b.yaml": "0123456789",
+		"c.yaml": "01234567890123456789",
+	})
+	assert.Equal(t, []map[string]string{
+		{"a.yaml": "0123456789"},
+		{"b.yaml": "0123456789"},
+		// a manifest larger than the size is never split
+		{"c.yaml": "01234567890123456789"},
+	}, shards)
+
+	assert.Equal(t, []map[string]string{{}}, splitExportManifests(nil))
+}
+
+func TestWriteExportConfigMaps(t *testing.T) {
+	previous := ExportConfigMapSize
+	defer func() { ExportConfigMapSize = previous }()
+	ExportConfigMapSize = 20
+
+	scheme := getFinalizerTestScheme(t)
+	export := &v1beta1.GrafanaDashboardExport{ObjectMeta: metav1.ObjectMeta{Name: "export", Namespace: "monitoring", UID: "export-uid"}}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(export).Build()
+	r := &GrafanaDashboardExportReconciler{Client: k8sClient, Scheme: scheme}
+
+	names, err := r.writeExportConfigMaps(context.Background(), export, map[string]string{
+		"a.yaml": "0123456789",
+		"b.yaml": "0123456789",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"export", "export-1"}, names)
+
+	configMap := &v1.ConfigMap{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "monitoring", Name: "export-1"}, configMap))
+	assert.Equal(t, map[string]string{"b.yaml": "0123456789"}, configMap.Data)
+
+	// config maps that are no longer needed are removed
+	export.Status.ConfigMaps = names
+	names, err = r.writeExportConfigMaps(context.Background(), export, map[string]string{"a.yaml": "0123456789"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"export"}, names)
+
+	err = k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "monitoring", Name: "export-1"}, configMap)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestHashExportManifests(t *testing.T) {
+	hash := hashExportManifests(map[string]string{"a.yaml": "a", "b.yaml": "b"})
+	assert.Equal(t, hash, hashExportManifests(map[string]string{"b.yaml": "b", "a.yaml": "a"}))
+	assert.NotEqual(t, hash, hashExportManifests(map[string]string{"a.yaml": "a", "b.yaml": "changed"}))
+}
@@ -17,8 +17,13 @@ limitations under the License.
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -31,23 +36,37 @@ import (
 	gapi "github.com/grafana/grafana-api-golang-client"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
 
 	v1beta1 "github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
 )
 
+const (
+	// field indexes used to find the datasources referencing a secret or config map
+	datasourceSecretIndexKey    = "secretReferences"
+	datasourceConfigMapIndexKey = "configMapReferences"
+)
+
 // GrafanaDatasourceReconciler reconciles a GrafanaDatasource object
 type GrafanaDatasourceReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Log    logr.Logger
+	Scheme   *runtime.Scheme
+	Log      logr.Logger
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadatasources,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadatasources/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadatasources/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *GrafanaDatasourceReconciler) syncDatasources(ctx context.Context) (ctrl.Result, error) {
 	syncLog := log.FromContext(ctx)
@@ -91,6 +110,17 @@ func (r *GrafanaDatasourceReconciler) syncDatasources(ctx context.Context) (ctrl
 	// delete all dashboards that no longer have a cr
 	for grafana, datasources := range datasourcesToDelete {
 		grafana := grafana
+		if grafana.IsFileProvisioning() {
+			for _, datasource := range datasources {
+				err = r.onProvisionedDatasourceDeleted(ctx, grafana, datasource.Namespace(), datasource.Name())
+				if err != nil {
+					return ctrl.Result{Requeue: false}, err
+				}
+				datasourcesSynced += 1
+			}
+			continue
+		}
+
 		grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, grafana)
 		if err != nil {
 			return ctrl.Result{Requeue: true}, err
@@ -114,9 +144,12 @@ func (r *GrafanaDatasourceReconciler) syncDatasources(ctx context.Context) (ctrl
 				}
 			}
 
-			err = grafanaClient.DeleteDataSource(instanceDatasource.ID)
-			if err != nil {
-				return ctrl.Result{Requeue: false}, err
+			// the status entry of a datasource that is already gone from the instance is dropped without a delete
+			if instanceDatasource != nil {
+				err = grafanaClient.DeleteDataSource(instanceDatasource.ID)
+				if err != nil && !strings.Contains(err.Error(), "status: 404") {
+					return ctrl.Result{Requeue: false}, err
+				}
 			}
 
 			grafana.Status.Datasources = grafana.Status.Datasources.Remove(namespace, name)
@@ -157,7 +190,8 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}, datasource)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			err = r.onDatasourceDeleted(ctx, req.Namespace, req.Name)
+			deleteDatasourceHealthMetrics(req.Namespace, req.Name)
+			err = r.onDatasourceDeleted(ctx, req.Namespace, req.Name, nil)
 			if err != nil {
 				return ctrl.Result{RequeueAfter: RequeueDelay}, err
 			}
@@ -166,6 +200,18 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		controllerLog.Error(err, "error getting grafana dashboard cr")
 		return ctrl.Result{RequeueAfter: RequeueDelay}, err
 	}
+	deleting, err := reconcileFinalizer(ctx, r.Client, datasource, func() error {
+		deleteDatasourceHealthMetrics(datasource.Namespace, datasource.Name)
+		return r.finalizeDatasource(ctx, datasource)
+	})
+	if err != nil {
+		controllerLog.Error(err, "error finalizing grafana datasource cr", "name", datasource.Name, "namespace", datasource.Namespace)
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+	if deleting {
+		return ctrl.Result{}, nil
+	}
+
 	instances, err := r.GetMatchingDatasourceInstances(ctx, datasource, r.Client)
 	if err != nil {
 		controllerLog.Error(err, "could not find matching instances", "name", datasource.Name, "namespace", datasource.Namespace)
@@ -174,7 +220,9 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	controllerLog.Info("found matching Grafana instances for datasource", "count", len(instances.Items))
 
-	success := true
+	previous := datasource.Status.DeepCopy()
+	state := newSyncState(datasource.Status.Instances)
+	var health []v1beta1.GrafanaDatasourceHealth
 	for _, grafana := range instances.Items {
 		// check if this is a cross namespace import
 		if grafana.Namespace != datasource.Namespace && !datasource.IsAllowCrossNamespaceImport() {
@@ -186,7 +234,7 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		// the instance or route might not yet be ready
 		if grafana.Status.Stage != v1beta1.OperatorStageComplete || grafana.Status.StageStatus != v1beta1.OperatorStageResultSuccess {
 			controllerLog.Info("grafana instance not ready", "grafana", grafana.Name)
-			success = false
+			state.addNotReady(&grafana)
 			continue
 		}
 
@@ -196,148 +244,366 @@ func (r *GrafanaDatasourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			// grafana reconciler will pick them upi
 			err = ReconcilePlugins(ctx, r.Client, r.Scheme, &grafana, datasource.Spec.Plugins, fmt.Sprintf("%v-datasource", datasource.Name))
 			if err != nil {
-				success = false
+				state.addError(&grafana, err)
 				controllerLog.Error(err, "error reconciling plugins", "datasource", datasource.Name, "grafana", grafana.Name)
 			}
 		}
 
 		// then import the dashboard into the matching grafana instances
-		err = r.onDatasourceCreated(ctx, &grafana, datasource)
+		var hash string
+		if grafana.IsFileProvisioning() {
+			hash, err = r.onDatasourceProvisioned(ctx, &grafana, datasource)
+		} else {
+			hash, err = r.onDatasourceCreated(ctx, &grafana, datasource)
+		}
 		if err != nil {
-			success = false
+			state.addError(&grafana, err)
 			datasource.Status.LastMessage = err.Error()
 			controllerLog.Error(err, "error reconciling dashboard", "datasource", datasource.Name, "grafana", grafana.Name)
+		} else if datasource.Spec.HealthCheck != nil {
+			changed := !datasource.Unchanged(&grafana, hash)
+			health = append(health, r.checkDatasourceHealth(ctx, &grafana, datasource, datasource.Status.Health, changed))
 		}
+		state.addSynced(&grafana, grafana.Status.Datasources.GetUid(datasource.Namespace, datasource.Name), hash)
 	}
+	datasource.Status.Instances = state.getInstances()
 
-	// if the datasource was successfully synced in all instances, wait for its re-sync period
-	if success {
+	if datasource.Spec.HealthCheck == nil && len(datasource.Status.Health) > 0 {
+		deleteDatasourceHealthMetrics(datasource.Namespace, datasource.Name)
+	}
+	datasource.Status.Health = health
+	datasource.Status.SetHealthCondition(datasource.Generation)
+
+	reason, message := state.getReason()
+	datasource.Status.SetSyncConditions(datasource.Generation, reason, message)
+
+	if state.success() {
 		datasource.Status.LastMessage = ""
-		return ctrl.Result{RequeueAfter: datasource.GetResyncPeriod()}, r.UpdateStatus(ctx, datasource)
-	} else {
-		// if there was an issue with the datasource, update the status
-		return ctrl.Result{RequeueAfter: RequeueDelay}, r.UpdateStatus(ctx, datasource)
 	}
+
+	if !reflect.DeepEqual(previous, &datasource.Status) {
+		err = r.Client.Status().Update(ctx, datasource)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: RequeueDelay}, err
+		}
+	}
+
+	// if the datasource was successfully synced in all instances, wait for its re-sync period or the next health check
+	if state.success() {
+		return ctrl.Result{RequeueAfter: datasource.GetRequeueAfter()}, nil
+	}
+	return ctrl.Result{RequeueAfter: RequeueDelay}, nil
 }
 
-func (r *GrafanaDatasourceReconciler) onDatasourceDeleted(ctx context.Context, namespace string, name string) error {
-	list := v1beta1.GrafanaList{}
-	opts := []client.ListOption{}
-	err := r.Client.List(ctx, &list, opts...)
+// onDatasourceDeleted removes the datasource from all instances it was synced to
+func (r *GrafanaDatasourceReconciler) onDatasourceDeleted(ctx context.Context, namespace string, name string, synced v1beta1.InstanceSyncStatusList) error {
+	instances, err := getSyncedInstances(ctx, r.Client, namespace, name, synced, datasourceStatusList)
 	if err != nil {
 		return err
 	}
 
-	for _, grafana := range list.Items {
-		grafana := grafana
-		if found, uid := grafana.Status.Datasources.Find(namespace, name); found {
-			grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, &grafana)
-			if err != nil {
-				return err
-			}
+	for _, instance := range instances {
+		if instance.grafana.IsFileProvisioning() {
+			err = r.onProvisionedDatasourceDeleted(ctx, instance.grafana, namespace, name)
+		} else {
+			err = r.deleteDatasource(ctx, instance.grafana, namespace, name, instance.uid)
+		}
+		if err != nil {
+			return err
+		}
+	}
 
-			datasource, err := grafanaClient.DataSourceByUID(*uid)
-			if err != nil {
-				return err
-			}
+	return nil
+}
 
-			err = grafanaClient.DeleteDataSource(datasource.ID)
-			if err != nil {
-				if !strings.Contains(err.Error(), "status: 404") {
-					return err
-				}
-			}
+// deleteDatasource removes the datasource from an instance
+func (r *GrafanaDatasourceReconciler) deleteDatasource(ctx context.Context, grafana *v1beta1.Grafana, namespace string, name string, uid string) error {
+	grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, grafana)
+	if err != nil {
+		return err
+	}
 
-			if grafana.IsInternal() {
-				err = ReconcilePlugins(ctx, r.Client, r.Scheme, &grafana, nil, fmt.Sprintf("%v-datasource", name))
-				if err != nil {
-					return err
-				}
+	datasource, err := grafanaClient.DataSourceByUID(uid)
+	if err != nil {
+		if !strings.Contains(err.Error(), "status: 404") {
+			return err
+		}
+	}
+
+	if datasource != nil {
+		err = grafanaClient.DeleteDataSource(datasource.ID)
+		if err != nil {
+			if !strings.Contains(err.Error(), "status: 404") {
+				return err
 			}
+		}
+	}
 
-			grafana.Status.Datasources = grafana.Status.Datasources.Remove(namespace, name)
-			return r.Client.Status().Update(ctx, &grafana)
+	if grafana.IsInternal() {
+		err = ReconcilePlugins(ctx, r.Client, r.Scheme, grafana, nil, fmt.Sprintf("%v-datasource", name))
+		if err != nil {
+			return err
 		}
 	}
 
-	return nil
+	grafana.Status.Datasources = grafana.Status.Datasources.Remove(namespace, name)
+	return r.Client.Status().Update(ctx, grafana)
+}
+
+// finalizeDatasource removes the datasource from the instances before the CR is deleted, unless the deletion policy
+// retains it
+func (r *GrafanaDatasourceReconciler) finalizeDatasource(ctx context.Context, cr *v1beta1.GrafanaDatasource) error {
+	if cr.GetDeletionPolicy() == v1beta1.DeletionPolicyRetain {
+		return releaseContent(ctx, r.Client, cr.Namespace, cr.Name, datasourceStatusList)
+	}
+	return r.onDatasourceDeleted(ctx, cr.Namespace, cr.Name, cr.Status.Instances)
 }
 
-func (r *GrafanaDatasourceReconciler) onDatasourceCreated(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDatasource) error {
+func (r *GrafanaDatasourceReconciler) onDatasourceCreated(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDatasource) (string, error) {
 	if cr.Spec.Datasource == nil {
-		return nil
+		return "", nil
 	}
 
 	if grafana.IsExternal() && cr.Spec.Plugins != nil {
-		return fmt.Errorf("external grafana instances don't support plugins, please remove spec.plugins from your datasource cr")
+		return "", fmt.Errorf("external grafana instances don't support plugins, please remove spec.plugins from your datasource cr")
 	}
 
 	grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, grafana)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	id, err := r.ExistingId(grafanaClient, cr)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	variables, err := r.CollectVariablesFromSecrets(ctx, cr)
+	datasourceBytes, valuesHash, err := r.renderDatasource(ctx, cr)
 	if err != nil {
-		return err
-	}
-
-	// always use the same uid for CR and datasource
-	cr.Spec.Datasource.UID = string(cr.UID)
-	datasourceBytes, err := cr.ExpandVariables(variables)
-	if err != nil {
-		return err
+		return "", err
 	}
+	hash := cr.HashWithValues(valuesHash)
 
 	switch {
 	case id == nil:
 		_, err = grafanaClient.NewDataSourceFromRawData(datasourceBytes)
 		if err != nil && !strings.Contains(err.Error(), "status: 409") {
-			return err
+			return "", err
 		}
-	case !cr.Unchanged():
+	case !cr.Unchanged(grafana, hash):
 		err := grafanaClient.UpdateDataSourceFromRawData(*id, datasourceBytes)
 		if err != nil {
-			return err
+			return "", err
 		}
 	default:
 		// datasource exists and is unchanged, nothing to do
-		return nil
+		return hash, nil
 	}
 
-	err = r.UpdateStatus(ctx, cr)
+	cr.Status.Hash = cr.Hash()
+
+	grafana.Status.Datasources = grafana.Status.Datasources.Add(cr.Namespace, cr.Name, string(cr.UID))
+	return hash, r.Client.Status().Update(ctx, grafana)
+}
+
+// onDatasourceProvisioned renders the datasource into the secret the instance provisions datasources from, the
+// instance is restarted to pick up the change
+func (r *GrafanaDatasourceReconciler) onDatasourceProvisioned(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDatasource) (string, error) {
+	if cr.Spec.Datasource == nil {
+		return "", nil
+	}
+
+	datasourceBytes, valuesHash, err := r.renderDatasource(ctx, cr)
 	if err != nil {
-		return err
+		return "", err
+	}
+	hash := cr.HashWithValues(valuesHash)
+
+	content, err := getDatasourceProvisioningFile(datasourceBytes)
+	if err != nil {
+		return "", err
+	}
+
+	err = ReconcileProvisionedDatasource(ctx, r.Client, r.Scheme, grafana, getProvisioningFileName(cr.Namespace, cr.Name, "yaml"), content)
+	if err != nil {
+		return "", err
 	}
 
+	cr.Status.Hash = cr.Hash()
+
+	if found, _ := grafana.Status.Datasources.Find(cr.Namespace, cr.Name); found {
+		return hash, nil
+	}
 	grafana.Status.Datasources = grafana.Status.Datasources.Add(cr.Namespace, cr.Name, string(cr.UID))
+	return hash, r.Client.Status().Update(ctx, grafana)
+}
+
+// onProvisionedDatasourceDeleted removes the file of a deleted datasource, the datasource is gone once the instance
+// restarted with its empty data volume
+func (r *GrafanaDatasourceReconciler) onProvisionedDatasourceDeleted(ctx context.Context, grafana *v1beta1.Grafana, namespace string, name string) error {
+	err := ReconcileProvisionedDatasource(ctx, r.Client, r.Scheme, grafana, getProvisioningFileName(namespace, name, "yaml"), nil)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+
+	err = ReconcilePlugins(ctx, r.Client, r.Scheme, grafana, nil, fmt.Sprintf("%v-datasource", name))
+	if err != nil {
+		return err
+	}
+
+	grafana.Status.Datasources = grafana.Status.Datasources.Remove(namespace, name)
 	return r.Client.Status().Update(ctx, grafana)
 }
 
-func (r *GrafanaDatasourceReconciler) UpdateStatus(ctx context.Context, cr *v1beta1.GrafanaDatasource) error {
-	cr.Status.Hash = cr.Hash()
-	return r.Client.Status().Update(ctx, cr)
+// getDatasourceProvisioningFile renders a datasource as provisioning file. Grafana expands environment variables in
+// provisioning files, dollar signs in the datasource are escaped.
+func getDatasourceProvisioningFile(datasourceBytes []byte) ([]byte, error) {
+	var datasource map[string]interface{}
+	err := json.Unmarshal(datasourceBytes, &datasource)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion":  1,
+		"datasources": []interface{}{datasource},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.ReplaceAll(content, []byte("$"), []byte("$$")), nil
 }
 
 func (r *GrafanaDatasourceReconciler) ExistingId(client *gapi.Client, cr *v1beta1.GrafanaDatasource) (*int64, error) {
-	datasources, err := client.DataSources()
+	datasource, err := client.DataSourceByUID(string(cr.UID))
 	if err != nil {
+		if strings.Contains(err.Error(), "status: 404") {
+			return nil, nil
+		}
 		return nil, err
 	}
-	for _, datasource := range datasources {
-		if datasource.UID == string(cr.UID) {
-			return &datasource.ID, nil
+	return &datasource.ID, nil
+}
+
+// renderDatasource returns the datasource json with the variables expanded and the values of valuesFrom injected,
+// together with a digest of the variables and values. Values are injected after the expansion, so they are never
+// expanded themselves.
+func (r *GrafanaDatasourceReconciler) renderDatasource(ctx context.Context, cr *v1beta1.GrafanaDatasource) ([]byte, string, error) {
+	versions := sourceVersions{}
+	variables, err := r.CollectVariablesFromSecrets(ctx, cr, versions)
+	if err != nil {
+		return nil, "", err
+	}
+
+	values, err := r.CollectValuesFrom(ctx, cr, versions)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// always use the same uid for CR and datasource
+	cr.Spec.Datasource.UID = string(cr.UID)
+	datasourceBytes, err := cr.ExpandVariables(variables)
+	if err != nil {
+		return nil, "", err
+	}
+
+	datasourceBytes, err = cr.InjectValues(datasourceBytes, values)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return datasourceBytes, hashDatasourceValues(versions), nil
+}
+
+// sourceVersions records the resource versions of the secrets and config maps variables and values were read from
+type sourceVersions map[string]string
+
+func (in sourceVersions) add(kind string, object client.Object) {
+	if in != nil {
+		in[fmt.Sprintf("%v/%v", kind, object.GetName())] = object.GetResourceVersion()
+	}
+}
+
+// hashDatasourceValues returns a digest of the resource versions of the secrets and config maps the variables and
+// values were read from, a rotated secret changes the digest and the datasource is updated in the instances. The
+// values themselves aren't hashed, the digest is stored in the status, which can be read without access to them.
+func hashDatasourceValues(versions sourceVersions) string {
+	if len(versions) == 0 {
+		return ""
+	}
+
+	entries := make([]string, 0, len(versions))
+	for key, version := range versions {
+		entries = append(entries, fmt.Sprintf("%v=%v", key, version))
+	}
+	sort.Strings(entries)
+
+	hash := sha256.New()
+	for _, entry := range entries {
+		hash.Write([]byte(entry))
+		hash.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// CollectValuesFrom returns the values of valuesFrom keyed by their target path, secrets and config maps must be in
+// the same namespace as the datasource
+func (r *GrafanaDatasourceReconciler) CollectValuesFrom(ctx context.Context, cr *v1beta1.GrafanaDatasource, versions sourceVersions) (map[string]string, error) {
+	result := map[string]string{}
+	for _, value := range cr.Spec.ValuesFrom {
+		source := value.ValueFrom
+		switch {
+		case source.SecretKeyRef != nil:
+			s := &v1.Secret{}
+			err := r.Client.Get(ctx, client.ObjectKey{Namespace: cr.Namespace, Name: source.SecretKeyRef.Name}, s)
+			if err != nil {
+				if errors.IsNotFound(err) && isOptional(source.SecretKeyRef.Optional) {
+					continue
+				}
+				return nil, err
+			}
+			versions.add("secret", s)
+
+			data, ok := s.Data[source.SecretKeyRef.Key]
+			if !ok {
+				if isOptional(source.SecretKeyRef.Optional) {
+					continue
+				}
+				return nil, fmt.Errorf("key %v not found in secret %v", source.SecretKeyRef.Key, source.SecretKeyRef.Name)
+			}
+			result[value.TargetPath] = string(data)
+		case source.ConfigMapKeyRef != nil:
+			cm := &v1.ConfigMap{}
+			err := r.Client.Get(ctx, client.ObjectKey{Namespace: cr.Namespace, Name: source.ConfigMapKeyRef.Name}, cm)
+			if err != nil {
+				if errors.IsNotFound(err) && isOptional(source.ConfigMapKeyRef.Optional) {
+					continue
+				}
+				return nil, err
+			}
+			versions.add("configmap", cm)
+
+			data, ok := cm.Data[source.ConfigMapKeyRef.Key]
+			if !ok {
+				if isOptional(source.ConfigMapKeyRef.Optional) {
+					continue
+				}
+				return nil, fmt.Errorf("key %v not found in config map %v", source.ConfigMapKeyRef.Key, source.ConfigMapKeyRef.Name)
+			}
+			result[value.TargetPath] = data
+		default:
+			return nil, fmt.Errorf("valuesFrom %v must reference a secret or a config map", value.TargetPath)
 		}
 	}
-	return nil, nil
+	return result, nil
 }
 
-func (r *GrafanaDatasourceReconciler) CollectVariablesFromSecrets(ctx context.Context, cr *v1beta1.GrafanaDatasource) (map[string][]byte, error) {
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+func (r *GrafanaDatasourceReconciler) CollectVariablesFromSecrets(ctx context.Context, cr *v1beta1.GrafanaDatasource, versions sourceVersions) (map[string][]byte, error) {
 	result := map[string][]byte{}
 	for _, secret := range cr.Spec.Secrets {
 		// secrets must be in the same namespace as the datasource
@@ -351,6 +617,7 @@ func (r *GrafanaDatasourceReconciler) CollectVariablesFromSecrets(ctx context.Co
 		if err != nil {
 			return nil, err
 		}
+		versions.add("secret", s)
 
 		for key, value := range s.Data {
 			result[key] = value
@@ -359,10 +626,79 @@ func (r *GrafanaDatasourceReconciler) CollectVariablesFromSecrets(ctx context.Co
 	return result, nil
 }
 
+// getDatasourceSecrets returns the names of all secrets the datasource reads variables or values from
+func getDatasourceSecrets(datasource *v1beta1.GrafanaDatasource) []string {
+	var names []string
+	for _, secret := range datasource.Spec.Secrets {
+		names = append(names, strings.TrimSpace(secret))
+	}
+
+	for _, value := range datasource.Spec.ValuesFrom {
+		if value.ValueFrom.SecretKeyRef != nil {
+			names = append(names, value.ValueFrom.SecretKeyRef.Name)
+		}
+	}
+	return names
+}
+
+// getDatasourceConfigMaps returns the names of all config maps the datasource reads values from
+func getDatasourceConfigMaps(datasource *v1beta1.GrafanaDatasource) []string {
+	var names []string
+	for _, value := range datasource.Spec.ValuesFrom {
+		if value.ValueFrom.ConfigMapKeyRef != nil {
+			names = append(names, value.ValueFrom.ConfigMapKeyRef.Name)
+		}
+	}
+	return names
+}
+
+// requestsForReferencingDatasources enqueues the datasources referencing the changed secret or config map, so that
+// rotated credentials reach the instances without waiting for the resync period
+func (r *GrafanaDatasourceReconciler) requestsForReferencingDatasources(indexKey string) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		list := &v1beta1.GrafanaDatasourceList{}
+		opts := []client.ListOption{
+			client.InNamespace(o.GetNamespace()),
+			client.MatchingFields{indexKey: o.GetName()},
+		}
+
+		err := r.Client.List(context.Background(), list, opts...)
+		if err != nil {
+			r.Log.Error(err, "error listing datasources referencing object", "namespace", o.GetNamespace(), "name", o.GetName())
+			return nil
+		}
+
+		requests := make([]reconcile.Request, 0, len(list.Items))
+		for _, datasource := range list.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: datasource.Namespace,
+				Name:      datasource.Name,
+			}})
+		}
+		return requests
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *GrafanaDatasourceReconciler) SetupWithManager(mgr ctrl.Manager, ctx context.Context) error {
-	err := ctrl.NewControllerManagedBy(mgr).
+	err := mgr.GetFieldIndexer().IndexField(ctx, &v1beta1.GrafanaDatasource{}, datasourceSecretIndexKey, func(o client.Object) []string {
+		return getDatasourceSecrets(o.(*v1beta1.GrafanaDatasource))
+	})
+	if err != nil {
+		return err
+	}
+
+	err = mgr.GetFieldIndexer().IndexField(ctx, &v1beta1.GrafanaDatasource{}, datasourceConfigMapIndexKey, func(o client.Object) []string {
+		return getDatasourceConfigMaps(o.(*v1beta1.GrafanaDatasource))
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.NewControllerManagedBy(mgr).
 		For(&v1beta1.GrafanaDatasource{}).
+		Watches(&source.Kind{Type: &v1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.requestsForReferencingDatasources(datasourceSecretIndexKey))).
+		Watches(&source.Kind{Type: &v1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.requestsForReferencingDatasources(datasourceConfigMapIndexKey))).
 		Complete(r)
 
 	if err == nil {
@@ -397,7 +733,7 @@ func (r *GrafanaDatasourceReconciler) SetupWithManager(mgr ctrl.Manager, ctx con
 }
 
 func (r *GrafanaDatasourceReconciler) GetMatchingDatasourceInstances(ctx context.Context, datasource *v1beta1.GrafanaDatasource, k8sClient client.Client) (v1beta1.GrafanaList, error) {
-	instances, err := GetMatchingInstances(ctx, k8sClient, datasource.Spec.InstanceSelector)
+	instances, err := GetMatchingInstances(ctx, k8sClient, datasource.Spec.InstanceSelector, datasource.Spec.InstanceNamespaceSelector)
 	if err != nil || len(instances.Items) == 0 {
 		datasource.Status.NoMatchingInstances = true
 		if err := r.Client.Status().Update(ctx, datasource); err != nil {
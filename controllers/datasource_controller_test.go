@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/model"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGetDatasourceProvisioningFile(t *testing.T) {
+	content, err := getDatasourceProvisioningFile([]byte(`{"uid":"abc","name":"prometheus","type":"prometheus","secureJsonData":{"password":"pa$word"}}`))
+	assert.NoError(t, err)
+
+	var file struct {
+		ApiVersion  int                      `json:"apiVersion"`
+		Datasources []map[string]interface{} `json:"datasources"`
+	}
+	assert.NoError(t, yaml.Unmarshal(content, &file))
+	assert.Equal(t, 1, file.ApiVersion)
+	assert.Len(t, file.Datasources, 1)
+	assert.Equal(t, "abc", file.Datasources[0]["uid"])
+
+	// Grafana would expand $word as environment variable
+	assert.Equal(t, map[string]interface{}{"password": "pa$$word"}, file.Datasources[0]["secureJsonData"])
+}
+
+func TestCollectValuesFrom(t *testing.T) {
+	optional := true
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus", Namespace: "default"},
+		Data:       map[string]string{"url": "http://prometheus:9090"},
+	}
+	r := &GrafanaDatasourceReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(secret, configMap).Build()}
+
+	cr := &v1beta1.GrafanaDatasource{
+		ObjectMeta: metav1.ObjectMeta{Name: "datasource", Namespace: "default"},
+		Spec: v1beta1.GrafanaDatasourceSpec{
+			ValuesFrom: []v1beta1.GrafanaDatasourceValueFrom{
+				{
+					TargetPath: "secureJsonData.password",
+					ValueFrom: v1beta1.GrafanaDatasourceValueFromSource{
+						SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "credentials"}, Key: "password"},
+					},
+				},
+				{
+					TargetPath: "url",
+					ValueFrom: v1beta1.GrafanaDatasourceValueFromSource{
+						ConfigMapKeyRef: &v1.ConfigMapKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "prometheus"}, Key: "url"},
+					},
+				},
+				{
+					TargetPath: "user",
+					ValueFrom: v1beta1.GrafanaDatasourceValueFromSource{
+						SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "missing"}, Key: "user", Optional: &optional},
+					},
+				},
+			},
+		},
+	}
+
+	versions := sourceVersions{}
+	values, err := r.CollectValuesFrom(context.Background(), cr, versions)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"secureJsonData.password": "secret", "url": "http://prometheus:9090"}, values)
+	assert.Equal(t, sourceVersions{"secret/credentials": "999", "configmap/prometheus": "999"}, versions)
+
+	// required keys have to exist
+	cr.Spec.ValuesFrom[0].ValueFrom.SecretKeyRef.Key = "token"
+	_, err = r.CollectValuesFrom(context.Background(), cr, nil)
+	assert.Error(t, err)
+}
+
+func TestHashDatasourceValues(t *testing.T) {
+	assert.Equal(t, "", hashDatasourceValues(nil))
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "default", ResourceVersion: "1"},
+		Data:       map[string][]byte{"PASSWORD": []byte("secret")},
+	}
+	versions := sourceVersions{}
+	versions.add("secret", secret)
+
+	hash := hashDatasourceValues(versions)
+	assert.NotEmpty(t, hash)
+	assert.Equal(t, hash, hashDatasourceValues(sourceVersions{"secret/credentials": "1"}))
+
+	// the values aren't part of the hash, a rotated secret changes it through its resource version
+	secret.ResourceVersion = "2"
+	versions.add("secret", secret)
+	assert.NotEqual(t, hash, hashDatasourceValues(versions))
+}
+
+func TestGetDatasourceReferences(t *testing.T) {
+	cr := &v1beta1.GrafanaDatasource{
+		Spec: v1beta1.GrafanaDatasourceSpec{
+			Secrets: []string{" credentials "},
+			ValuesFrom: []v1beta1.GrafanaDatasourceValueFrom{
+				{
+					TargetPath: "secureJsonData.password",
+					ValueFrom: v1beta1.GrafanaDatasourceValueFromSource{
+						SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "database"}, Key: "password"},
+					},
+				},
+				{
+					TargetPath: "url",
+					ValueFrom: v1beta1.GrafanaDatasourceValueFromSource{
+						ConfigMapKeyRef: &v1.ConfigMapKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "prometheus"}, Key: "url"},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"credentials", "database"}, getDatasourceSecrets(cr))
+	assert.Equal(t, []string{"prometheus"}, getDatasourceConfigMaps(cr))
+}
+
+func TestSyncDatasources_DropsMissingDatasources(t *testing.T) {
+	var deletes []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletes = append(deletes, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"},
+		Status: v1beta1.GrafanaStatus{
+			AdminUrl:    ts.URL,
+			Datasources: v1beta1.NamespacedResourceList{"monitoring/removed/removed-uid"},
+		},
+	}
+
+	scheme := getFinalizerTestScheme(t)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(grafana, model.GetGrafanaDeployment(grafana, nil)).Build()
+	r := &GrafanaDatasourceReconciler{Client: k8sClient, Scheme: scheme}
+
+	_, err := r.syncDatasources(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, deletes)
+
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(grafana), grafana))
+	assert.Empty(t, grafana.Status.Datasources)
+}
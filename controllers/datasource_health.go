@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	client2 "github.com/grafana-operator/grafana-operator-experimental/controllers/client"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkDatasourceHealth runs the health check of the datasource in an instance and records the result in the metrics.
+// The previous result is kept until the check interval passed, unless the datasource was just changed. An event is
+// emitted when the health of the datasource in the instance changed since the previous check.
+func (r *GrafanaDatasourceReconciler) checkDatasourceHealth(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaDatasource, previous []v1beta1.GrafanaDatasourceHealth, changed bool) v1beta1.GrafanaDatasourceHealth {
+	instance := fmt.Sprintf("%v/%v", grafana.Namespace, grafana.Name)
+
+	if last := getRecentHealth(previous, instance, cr.GetHealthCheckInterval()); last != nil && !changed {
+		setDatasourceHealthMetrics(cr, *last)
+		return *last
+	}
+
+	start := time.Now()
+	health, err := client2.CheckDatasourceHealth(ctx, r.Client, grafana, string(cr.UID))
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		health = &client2.DatasourceHealth{
+			Status:  v1beta1.DatasourceHealthError,
+			Message: fmt.Sprintf("health check failed: %v", err.Error()),
+		}
+	}
+
+	result := v1beta1.GrafanaDatasourceHealth{
+		Instance:            instance,
+		Status:              health.Status,
+		Message:             health.Message,
+		LatencyMilliseconds: latency,
+		LastCheckTime:       metav1.Now(),
+	}
+
+	setDatasourceHealthMetrics(cr, result)
+
+	if healthChanged(previous, result) {
+		if result.Status == v1beta1.DatasourceHealthOK {
+			r.Recorder.Eventf(cr, v1.EventTypeNormal, "DatasourceHealthy", "datasource is healthy in instance %v", instance)
+		} else {
+			r.Recorder.Eventf(cr, v1.EventTypeWarning, "DatasourceUnhealthy", "datasource is unhealthy in instance %v: %v", instance, result.Message)
+		}
+	}
+
+	return result
+}
+
+// getRecentHealth returns the previous result of the health check in an instance while the next check isn't due yet
+func getRecentHealth(previous []v1beta1.GrafanaDatasourceHealth, instance string, interval time.Duration) *v1beta1.GrafanaDatasourceHealth {
+	for _, health := range previous {
+		if health.Instance == instance && time.Since(health.LastCheckTime.Time) < interval {
+			health := health
+			return &health
+		}
+	}
+	return nil
+}
+
+// setDatasourceHealthMetrics records the result of a health check in the metrics
+func setDatasourceHealthMetrics(cr *v1beta1.GrafanaDatasource, result v1beta1.GrafanaDatasourceHealth) {
+	labels := prometheus.Labels{"namespace": cr.Namespace, "datasource": cr.Name, "instance": result.Instance}
+	healthy := 0.0
+	if result.Status == v1beta1.DatasourceHealthOK {
+		healthy = 1
+	}
+	metrics.DatasourceHealth.With(labels).Set(healthy)
+	metrics.DatasourceHealthCheckDuration.With(labels).Set(float64(result.LatencyMilliseconds))
+}
+
+// healthChanged returns true if the health of the datasource flipped, a datasource that is unhealthy in its first
+// check counts as change
+func healthChanged(previous []v1beta1.GrafanaDatasourceHealth, result v1beta1.GrafanaDatasourceHealth) bool {
+	for _, health := range previous {
+		if health.Instance == result.Instance {
+			return health.Status != result.Status
+		}
+	}
+	return result.Status != v1beta1.DatasourceHealthOK
+}
+
+// deleteDatasourceHealthMetrics removes the health metrics of a datasource that was deleted or doesn't check its
+// health anymore
+func deleteDatasourceHealthMetrics(namespace string, name string) {
+	labels := prometheus.Labels{"namespace": namespace, "datasource": name}
+	metrics.DatasourceHealth.DeletePartialMatch(labels)
+	metrics.DatasourceHealthCheckDuration.DeletePartialMatch(labels)
+}
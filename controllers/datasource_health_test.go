@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHealthChanged(t *testing.T) {
+	previous := []v1beta1.GrafanaDatasourceHealth{
+		{Instance: "grafana/a", Status: v1beta1.DatasourceHealthOK},
+		{Instance: "grafana/b", Status: v1beta1.DatasourceHealthError},
+	}
+
+	assert.False(t, healthChanged(previous, v1beta1.GrafanaDatasourceHealth{Instance: "grafana/a", Status: v1beta1.DatasourceHealthOK}))
+	assert.True(t, healthChanged(previous, v1beta1.GrafanaDatasourceHealth{Instance: "grafana/a", Status: v1beta1.DatasourceHealthError}))
+	assert.True(t, healthChanged(previous, v1beta1.GrafanaDatasourceHealth{Instance: "grafana/b", Status: v1beta1.DatasourceHealthOK}))
+
+	// first checks only count as change if the datasource is unhealthy
+	assert.False(t, healthChanged(previous, v1beta1.GrafanaDatasourceHealth{Instance: "grafana/c", Status: v1beta1.DatasourceHealthOK}))
+	assert.True(t, healthChanged(previous, v1beta1.GrafanaDatasourceHealth{Instance: "grafana/c", Status: v1beta1.DatasourceHealthError}))
+}
+
+func TestGetRecentHealth(t *testing.T) {
+	previous := []v1beta1.GrafanaDatasourceHealth{
+		{Instance: "grafana/recent", Status: v1beta1.DatasourceHealthOK, LastCheckTime: metav1.NewTime(time.Now().Add(-time.Minute))},
+		{Instance: "grafana/due", Status: v1beta1.DatasourceHealthOK, LastCheckTime: metav1.NewTime(time.Now().Add(-time.Hour))},
+	}
+
+	recent := getRecentHealth(previous, "grafana/recent", 5*time.Minute)
+	assert.NotNil(t, recent)
+	assert.Equal(t, "grafana/recent", recent.Instance)
+
+	assert.Nil(t, getRecentHealth(previous, "grafana/due", 5*time.Minute))
+	assert.Nil(t, getRecentHealth(previous, "grafana/new", 5*time.Minute))
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// GrafanaDatasourceTemplateReconciler reconciles a GrafanaDatasourceTemplate object
+type GrafanaDatasourceTemplateReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+	// namespaces are only watched by a cluster scoped operator, otherwise they are listed again on every resync
+	ClusterScoped bool
+}
+
+// datasourceTemplateValues are the values available in the templated fields of a datasource
+type datasourceTemplateValues struct {
+	Namespace string
+	Name      string
+	Labels    map[string]string
+}
+
+//+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadatasourcetemplates,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadatasourcetemplates/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadatasourcetemplates/finalizers,verbs=update
+
+// Reconcile generates a GrafanaDatasource for every namespace or object matching the selectors of the template and
+// deletes the generated datasources of namespaces and objects that no longer match or are gone. The generated
+// datasources are owned by the template and synced to the instances by the datasource controller.
+func (r *GrafanaDatasourceTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	controllerLog := log.FromContext(ctx)
+	r.Log = controllerLog
+
+	datasourceTemplate := &v1beta1.GrafanaDatasourceTemplate{}
+	err := r.Client.Get(ctx, client.ObjectKey{
+		Namespace: req.Namespace,
+		Name:      req.Name,
+	}, datasourceTemplate)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// the generated datasources are removed by the garbage collector
+			return ctrl.Result{}, nil
+		}
+		controllerLog.Error(err, "error getting grafana datasource template cr")
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+
+	names, err := r.generateDatasources(ctx, datasourceTemplate)
+	if err != nil {
+		controllerLog.Error(err, "error generating datasources", "template", datasourceTemplate.Name)
+		datasourceTemplate.Status.LastMessage = err.Error()
+		datasourceTemplate.Status.SetSyncConditions(datasourceTemplate.Generation, v1beta1.ReasonSyncFailed, err.Error())
+		if err := r.Client.Status().Update(ctx, datasourceTemplate); err != nil {
+			controllerLog.Error(err, "error updating grafana datasource template status")
+		}
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+
+	datasourceTemplate.Status.Datasources = names
+	datasourceTemplate.Status.LastMessage = ""
+	datasourceTemplate.Status.SetSyncConditions(datasourceTemplate.Generation, v1beta1.ReasonSynced, "")
+	err = r.Client.Status().Update(ctx, datasourceTemplate)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+
+	return ctrl.Result{RequeueAfter: datasourceTemplate.GetResyncPeriod()}, nil
+}
+
+// generateDatasources creates or updates the datasources of the matching namespaces or objects and deletes the
+// datasources of the template that are no longer needed, returns the sorted names of the generated datasources
+func (r *GrafanaDatasourceTemplateReconciler) generateDatasources(ctx context.Context, datasourceTemplate *v1beta1.GrafanaDatasourceTemplate) ([]string, error) {
+	objects, err := r.getTemplateObjects(ctx, datasourceTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	generated := map[string]bool{}
+	for _, object := range objects {
+		spec, err := renderDatasourceTemplate(datasourceTemplate, object)
+		if err != nil {
+			return nil, fmt.Errorf("error rendering datasource for %v: %v", getTemplateObjectKey(object), err)
+		}
+
+		datasource := &v1beta1.GrafanaDatasource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      datasourceTemplate.GetDatasourceName(object),
+				Namespace: datasourceTemplate.Namespace,
+			},
+		}
+		_, err = controllerutil.CreateOrUpdate(ctx, r.Client, datasource, func() error {
+			datasource.Spec = *spec
+			return controllerutil.SetControllerReference(datasourceTemplate, datasource, r.Scheme)
+		})
+		if err != nil {
+			return nil, err
+		}
+		generated[datasource.Name] = true
+	}
+
+	datasources := &v1beta1.GrafanaDatasourceList{}
+	err = r.Client.List(ctx, datasources, client.InNamespace(datasourceTemplate.Namespace))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, datasource := range datasources.Items {
+		datasource := datasource
+		if generated[datasource.Name] || !metav1.IsControlledBy(&datasource, datasourceTemplate) {
+			continue
+		}
+
+		err = r.Client.Delete(ctx, &datasource)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(generated))
+	for name := range generated {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// getTemplateObjects returns the namespaces matching the selector of the template, or the matching objects in them
+// if the template selects objects. Namespaces and objects that are being deleted lose their datasource right away.
+// Objects are listed as unstructured and thus read directly, without starting an informer for their kind.
+func (r *GrafanaDatasourceTemplateReconciler) getTemplateObjects(ctx context.Context, datasourceTemplate *v1beta1.GrafanaDatasourceTemplate) ([]metav1.Object, error) {
+	selector, err := metav1.LabelSelectorAsSelector(datasourceTemplate.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := &v1.NamespaceList{}
+	err = r.Client.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	objectSelector := labels.Everything()
+	if datasourceTemplate.Spec.Objects != nil && datasourceTemplate.Spec.Objects.Selector != nil {
+		objectSelector, err = metav1.LabelSelectorAsSelector(datasourceTemplate.Spec.Objects.Selector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var objects []metav1.Object
+	for i := range namespaces.Items {
+		namespace := &namespaces.Items[i]
+		if namespace.DeletionTimestamp != nil || namespace.Status.Phase == v1.NamespaceTerminating {
+			continue
+		}
+
+		if datasourceTemplate.Spec.Objects == nil {
+			objects = append(objects, namespace)
+			continue
+		}
+
+		list := &unstructured.UnstructuredList{}
+		list.SetAPIVersion(datasourceTemplate.Spec.Objects.APIVersion)
+		list.SetKind(datasourceTemplate.Spec.Objects.Kind + "List")
+		err = r.Client.List(ctx, list, client.InNamespace(namespace.Name), client.MatchingLabelsSelector{Selector: objectSelector})
+		if err != nil {
+			return nil, err
+		}
+
+		for j := range list.Items {
+			if list.Items[j].GetDeletionTimestamp() == nil {
+				objects = append(objects, &list.Items[j])
+			}
+		}
+	}
+	return objects, nil
+}
+
+// getTemplateObjectKey returns the name of a namespace or the namespace/name of an object for messages
+func getTemplateObjectKey(object metav1.Object) string {
+	if object.GetNamespace() == "" {
+		return "namespace " + object.GetName()
+	}
+	return object.GetNamespace() + "/" + object.GetName()
+}
+
+// renderDatasourceTemplate returns the spec of the datasource generated for a namespace or object, the string values
+// of the datasource are executed as templates
+func renderDatasourceTemplate(datasourceTemplate *v1beta1.GrafanaDatasourceTemplate, object metav1.Object) (*v1beta1.GrafanaDatasourceSpec, error) {
+	spec := datasourceTemplate.Spec.Template.DeepCopy()
+	if spec.Datasource == nil {
+		return spec, nil
+	}
+
+	raw, err := json.Marshal(spec.Datasource)
+	if err != nil {
+		return nil, err
+	}
+
+	var datasource interface{}
+	err = json.Unmarshal(raw, &datasource)
+	if err != nil {
+		return nil, err
+	}
+
+	values := datasourceTemplateValues{
+		Namespace: object.GetNamespace(),
+		Name:      object.GetName(),
+		Labels:    object.GetLabels(),
+	}
+	// a namespace is its own namespace
+	if values.Namespace == "" {
+		values.Namespace = object.GetName()
+	}
+	datasource, err = renderDatasourceTemplateValue(datasource, values)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err = json.Marshal(datasource)
+	if err != nil {
+		return nil, err
+	}
+
+	spec.Datasource = &v1beta1.GrafanaDatasourceInternal{}
+	err = json.Unmarshal(raw, spec.Datasource)
+	if err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// renderDatasourceTemplateValue executes the templates in all strings of a json value, the rendered strings are set
+// in the parsed value, so they don't need to be escaped
+func renderDatasourceTemplateValue(value interface{}, values datasourceTemplateValues) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			rendered, err := renderDatasourceTemplateValue(field, values)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = rendered
+		}
+	case []interface{}:
+		for i, item := range v {
+			rendered, err := renderDatasourceTemplateValue(item, values)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = rendered
+		}
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, nil
+		}
+
+		tmpl, err := template.New("datasource").Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, err
+		}
+
+		var rendered bytes.Buffer
+		err = tmpl.Execute(&rendered, values)
+		if err != nil {
+			return nil, err
+		}
+		return rendered.String(), nil
+	}
+	return value, nil
+}
+
+// requestsForNamespace enqueues all templates when a namespace is created, relabeled or deleted
+func (r *GrafanaDatasourceTemplateReconciler) requestsForNamespace(o client.Object) []reconcile.Request {
+	list := &v1beta1.GrafanaDatasourceTemplateList{}
+	err := r.Client.List(context.Background(), list)
+	if err != nil {
+		r.Log.Error(err, "error listing datasource templates", "namespace", o.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, datasourceTemplate := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: datasourceTemplate.Namespace,
+			Name:      datasourceTemplate.Name,
+		}})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager. Namespaces are only watched with cluster scope, a
+// namespace scoped operator can't watch them. Changes of namespaces and of selected objects are picked up on the
+// next resync otherwise.
+func (r *GrafanaDatasourceTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.GrafanaDatasourceTemplate{}).
+		Owns(&v1beta1.GrafanaDatasource{})
+	if r.ClusterScoped {
+		builder = builder.Watches(&source.Kind{Type: &v1.Namespace{}}, handler.EnqueueRequestsFromMapFunc(r.requestsForNamespace))
+	}
+	return builder.Complete(r)
+}
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func getTestDatasourceTemplate() *v1beta1.GrafanaDatasourceTemplate {
+	return &v1beta1.GrafanaDatasourceTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "loki", Namespace: "grafana", UID: "template-uid"},
+		Spec: v1beta1.GrafanaDatasourceTemplateSpec{
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"logs": "loki"}},
+			Template: v1beta1.GrafanaDatasourceSpec{
+				InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"dashboards": "grafana"}},
+				Datasource: &v1beta1.GrafanaDatasourceInternal{
+					Name:           "loki-{{ .Namespace }}",
+					Type:           "loki",
+					URL:            "http://loki-{{ .Labels.cluster }}:3100",
+					JSONData:       []byte(`{"httpHeaderName1":"X-Scope-OrgID","maxLines":1000}`),
+					SecureJSONData: []byte(`{"httpHeaderValue1":"{{ .Namespace }}"}`),
+				},
+			},
+		},
+	}
+}
+
+func TestRenderDatasourceTemplate(t *testing.T) {
+	namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"cluster": "eu"}}}
+
+	spec, err := renderDatasourceTemplate(getTestDatasourceTemplate(), namespace)
+	assert.NoError(t, err)
+	assert.Equal(t, "loki-team-a", spec.Datasource.Name)
+	assert.Equal(t, "http://loki-eu:3100", spec.Datasource.URL)
+	assert.JSONEq(t, `{"httpHeaderName1":"X-Scope-OrgID","maxLines":1000}`, string(spec.Datasource.JSONData))
+	assert.JSONEq(t, `{"httpHeaderValue1":"team-a"}`, string(spec.Datasource.SecureJSONData))
+
+	// labels the namespace doesn't have are an error
+	namespace.Labels = nil
+	_, err = renderDatasourceTemplate(getTestDatasourceTemplate(), namespace)
+	assert.Error(t, err)
+}
+
+func TestGenerateDatasources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	datasourceTemplate := getTestDatasourceTemplate()
+	controller := true
+	stale := &v1beta1.GrafanaDatasource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "loki-removed",
+			Namespace: "grafana",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "grafana.integreatly.org/v1beta1",
+				Kind:       "GrafanaDatasourceTemplate",
+				Name:       "loki",
+				UID:        "template-uid",
+				Controller: &controller,
+			}},
+		},
+	}
+	unrelated := &v1beta1.GrafanaDatasource{ObjectMeta: metav1.ObjectMeta{Name: "prometheus", Namespace: "grafana"}}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		datasourceTemplate,
+		stale,
+		unrelated,
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"logs": "loki", "cluster": "eu"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"logs": "loki", "cluster": "us"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c", Labels: map[string]string{"cluster": "us"}}},
+	).Build()
+
+	r := &GrafanaDatasourceTemplateReconciler{Client: k8sClient, Scheme: scheme}
+	names, err := r.generateDatasources(context.Background(), datasourceTemplate)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"loki-team-a", "loki-team-b"}, names)
+
+	datasource := &v1beta1.GrafanaDatasource{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "grafana", Name: "loki-team-b"}, datasource))
+	assert.Equal(t, "http://loki-us:3100", datasource.Spec.Datasource.URL)
+	assert.True(t, metav1.IsControlledBy(datasource, datasourceTemplate))
+
+	// datasources of namespaces that don't match anymore are deleted, others are kept
+	err = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(stale), &v1beta1.GrafanaDatasource{})
+	assert.True(t, errors.IsNotFound(err))
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(unrelated), &v1beta1.GrafanaDatasource{}))
+}
+
+func TestGenerateDatasources_Objects(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	datasourceTemplate := getTestDatasourceTemplate()
+	datasourceTemplate.Spec.Objects = &v1beta1.GrafanaDatasourceTemplateObjects{
+		APIVersion: "v1",
+		Kind:       "ServiceAccount",
+		Selector:   &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "true"}},
+	}
+	datasourceTemplate.Spec.Template.Datasource.Name = "loki-{{ .Namespace }}-{{ .Name }}"
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		datasourceTemplate,
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"logs": "loki"}}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+		&v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "tenant-1", Namespace: "team-a", Labels: map[string]string{"tenant": "true", "cluster": "eu"}}},
+		&v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "team-a"}},
+		&v1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "tenant-2", Namespace: "team-c", Labels: map[string]string{"tenant": "true", "cluster": "eu"}}},
+	).Build()
+
+	r := &GrafanaDatasourceTemplateReconciler{Client: k8sClient, Scheme: scheme}
+	names, err := r.generateDatasources(context.Background(), datasourceTemplate)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"loki-team-a-tenant-1"}, names)
+
+	datasource := &v1beta1.GrafanaDatasource{}
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKey{Namespace: "grafana", Name: "loki-team-a-tenant-1"}, datasource))
+	assert.Equal(t, "loki-team-a-tenant-1", datasource.Spec.Datasource.Name)
+	assert.Equal(t, "http://loki-eu:3100", datasource.Spec.Datasource.URL)
+	assert.JSONEq(t, `{"httpHeaderValue1":"team-a"}`, string(datasource.Spec.Datasource.SecureJSONData))
+}
+
+// recordingInformers records the kinds informers are started for
+type recordingInformers struct {
+	informertest.FakeInformers
+	lock  sync.Mutex
+	kinds map[string]bool
+}
+
+func (c *recordingInformers) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	gvks, _, err := c.Scheme.ObjectKinds(obj)
+	if err != nil {
+		return nil, err
+	}
+	c.kinds[gvks[0].Kind] = true
+	return c.FakeInformers.GetInformer(ctx, obj)
+}
+
+func (c *recordingInformers) hasInformer(kind string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.kinds[kind]
+}
+
+func startDatasourceTemplateController(t *testing.T, clusterScoped bool) *recordingInformers {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	informers := &recordingInformers{FakeInformers: informertest.FakeInformers{Scheme: scheme}, kinds: map[string]bool{}}
+	mgr, err := ctrl.NewManager(&rest.Config{Host: "http://localhost:1"}, ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: "0",
+		MapperProvider: func(*rest.Config) (meta.RESTMapper, error) {
+			return meta.NewDefaultRESTMapper([]schema.GroupVersion{v1.SchemeGroupVersion, v1beta1.GroupVersion}), nil
+		},
+		NewCache: func(*rest.Config, cache.Options) (cache.Cache, error) {
+			return informers, nil
+		},
+	})
+	assert.NoError(t, err)
+
+	r := &GrafanaDatasourceTemplateReconciler{Client: mgr.GetClient(), Scheme: scheme, ClusterScoped: clusterScoped}
+	assert.NoError(t, r.SetupWithManager(mgr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, mgr.Start(ctx))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	assert.Eventually(t, func() bool {
+		return informers.hasInformer("GrafanaDatasourceTemplate") && informers.hasInformer("GrafanaDatasource")
+	}, 5*time.Second, 10*time.Millisecond)
+	return informers
+}
+
+func TestDatasourceTemplateController_NamespaceWatch(t *testing.T) {
+	informers := startDatasourceTemplateController(t, true)
+	assert.Eventually(t, func() bool {
+		return informers.hasInformer("Namespace")
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDatasourceTemplateController_NamespaceScoped(t *testing.T) {
+	// a namespace scoped operator can't watch namespaces, they are listed on every resync instead
+	informers := startDatasourceTemplateController(t, false)
+	assert.False(t, informers.hasInformer("Namespace"))
+}
+
+func TestDatasourceTemplateReconcile_Resync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	datasourceTemplate := getTestDatasourceTemplate()
+	datasourceTemplate.Spec.ResyncPeriod = "1m"
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(datasourceTemplate).Build()
+
+	r := &GrafanaDatasourceTemplateReconciler{Client: k8sClient, Scheme: scheme}
+	request := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(datasourceTemplate)}
+	result, err := r.Reconcile(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+
+	// namespaces created in the meantime are picked up on the next resync without a watch
+	assert.NoError(t, k8sClient.Create(context.Background(), &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"logs": "loki", "cluster": "eu"}}}))
+	_, err = r.Reconcile(context.Background(), request)
+	assert.NoError(t, err)
+	assert.NoError(t, k8sClient.Get(context.Background(), request.NamespacedName, datasourceTemplate))
+	assert.Equal(t, []string{"loki-team-a"}, datasourceTemplate.Status.Datasources)
+}
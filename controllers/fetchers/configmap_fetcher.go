@@ -0,0 +1,39 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FetchDashboardFromConfigMap reads the dashboard json from a key of a config map in the dashboard's namespace
+func FetchDashboardFromConfigMap(ctx context.Context, c client.Client, dashboard *v1beta1.GrafanaDashboard) ([]byte, error) {
+	ref := dashboard.Spec.ConfigMapRef
+	if ref == nil {
+		return nil, fmt.Errorf("no config map reference found for dashboard %v", dashboard.Name)
+	}
+
+	configMap := &v1.ConfigMap{}
+	selector := client.ObjectKey{
+		Namespace: dashboard.Namespace,
+		Name:      ref.Name,
+	}
+
+	err := c.Get(ctx, selector, configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if val, ok := configMap.Data[ref.Key]; ok {
+		return []byte(val), nil
+	}
+
+	if val, ok := configMap.BinaryData[ref.Key]; ok {
+		return val, nil
+	}
+
+	return nil, fmt.Errorf("key %v not found in config map %v/%v", ref.Key, dashboard.Namespace, ref.Name)
+}
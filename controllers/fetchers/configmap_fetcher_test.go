@@ -0,0 +1,60 @@
+package fetchers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFetchDashboardFromConfigMap(t *testing.T) {
+	dashboardJSON := `{"dummyField": "dummyData"}`
+
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "dashboards", Namespace: "grafana"},
+			Data:       map[string]string{"dashboard.json": dashboardJSON},
+			BinaryData: map[string][]byte{"binary.json": []byte(dashboardJSON)},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "dashboards", Namespace: "grafana"},
+			Data:       map[string][]byte{"dashboard.json": []byte(dashboardJSON)},
+		},
+	).Build()
+
+	dashboard := func(configMapKey string, secretKey string) *v1beta1.GrafanaDashboard {
+		cr := &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "grafana"},
+		}
+		ref := v1.LocalObjectReference{Name: "dashboards"}
+		if configMapKey != "" {
+			cr.Spec.ConfigMapRef = &v1.ConfigMapKeySelector{LocalObjectReference: ref, Key: configMapKey}
+		}
+		if secretKey != "" {
+			cr.Spec.SecretRef = &v1.SecretKeySelector{LocalObjectReference: ref, Key: secretKey}
+		}
+		return cr
+	}
+
+	content, err := FetchDashboardFromConfigMap(context.Background(), k8sClient, dashboard("dashboard.json", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(dashboardJSON), content)
+
+	content, err = FetchDashboardFromConfigMap(context.Background(), k8sClient, dashboard("binary.json", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(dashboardJSON), content)
+
+	_, err = FetchDashboardFromConfigMap(context.Background(), k8sClient, dashboard("missing.json", ""))
+	assert.Error(t, err)
+
+	content, err = FetchDashboardFromSecret(context.Background(), k8sClient, dashboard("", "dashboard.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(dashboardJSON), content)
+
+	_, err = FetchDashboardFromSecret(context.Background(), k8sClient, dashboard("", "missing.json"))
+	assert.Error(t, err)
+}
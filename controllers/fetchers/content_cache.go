@@ -0,0 +1,140 @@
+package fetchers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// MaxStatusContentCacheSize is the size in bytes above which the gzipped content cache is stored in config maps
+// instead of the status, 0 always stores it in the status
+var MaxStatusContentCacheSize = 256 * 1024
+
+// ContentCacheChunkSize is the maximum size in bytes of the content stored in a single config map, the size of a
+// config map is limited to 1MiB
+var ContentCacheChunkSize = 512 * 1024
+
+const contentCacheKey = "content"
+
+// LoadContentCache reads a content cache stored in config maps back into the status. A missing or outdated chunk is
+// treated like an expired cache and leaves the status untouched.
+func LoadContentCache(ctx context.Context, c client.Client, namespace string, status *v1beta1.GrafanaDashboardStatus) error {
+	ref := status.ContentCacheRef
+	if ref == nil || len(status.ContentCache) > 0 {
+		return nil
+	}
+
+	var content []byte
+	for _, name := range ref.ConfigMaps {
+		configMap := &v1.ConfigMap{}
+		err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, configMap)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		content = append(content, configMap.BinaryData[contentCacheKey]...)
+	}
+
+	if hashContentCache(content) != ref.Hash {
+		return nil
+	}
+
+	status.ContentCache = content
+	return nil
+}
+
+// StoreContentCache moves a content cache exceeding MaxStatusContentCacheSize from the status into config maps. The
+// owner is the controller of the config maps, they are garbage collected when it is deleted.
+func StoreContentCache(ctx context.Context, c client.Client, owner metav1.Object, gvk schema.GroupVersionKind, status *v1beta1.GrafanaDashboardStatus) error {
+	content := status.ContentCache
+	previous := status.ContentCacheRef
+
+	// nothing cached, or the stored cache couldn't be loaded and is kept
+	if len(content) == 0 {
+		return nil
+	}
+
+	if MaxStatusContentCacheSize <= 0 || len(content) <= MaxStatusContentCacheSize {
+		status.ContentCacheRef = nil
+		return deleteContentCacheChunks(ctx, c, owner.GetNamespace(), previous, 0)
+	}
+
+	hash := hashContentCache(content)
+	if previous != nil && previous.Hash == hash {
+		status.ContentCache = nil
+		return nil
+	}
+
+	ref := &v1beta1.ContentCacheReference{
+		Hash: hash,
+	}
+	ownerRef := metav1.NewControllerRef(owner, gvk)
+
+	for i := 0; len(content) > 0; i++ {
+		size := ContentCacheChunkSize
+		if size <= 0 || size > len(content) {
+			size = len(content)
+		}
+		chunk := content[:size]
+		content = content[size:]
+
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      v1beta1.TruncateName(fmt.Sprintf("%v-%v-content-%d", strings.ToLower(gvk.Kind), owner.GetName(), i)),
+				Namespace: owner.GetNamespace(),
+			},
+		}
+		_, err := controllerutil.CreateOrUpdate(ctx, c, configMap, func() error {
+			configMap.OwnerReferences = []metav1.OwnerReference{*ownerRef}
+			configMap.BinaryData = map[string][]byte{
+				contentCacheKey: chunk,
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store content cache of %v %v/%v: %v", gvk.Kind, owner.GetNamespace(), owner.GetName(), err)
+		}
+		ref.ConfigMaps = append(ref.ConfigMaps, configMap.Name)
+	}
+
+	status.ContentCacheRef = ref
+	status.ContentCache = nil
+	return deleteContentCacheChunks(ctx, c, owner.GetNamespace(), previous, len(ref.ConfigMaps))
+}
+
+// deleteContentCacheChunks deletes the config maps of a stored content cache, except for the first chunks that were
+// overwritten
+func deleteContentCacheChunks(ctx context.Context, c client.Client, namespace string, ref *v1beta1.ContentCacheReference, keep int) error {
+	if ref == nil || len(ref.ConfigMaps) <= keep {
+		return nil
+	}
+
+	for _, name := range ref.ConfigMaps[keep:] {
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+		err := c.Delete(ctx, configMap)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func hashContentCache(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
@@ -0,0 +1,98 @@
+package fetchers
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestContentCache(t *testing.T) {
+	maxSize, chunkSize := MaxStatusContentCacheSize, ContentCacheChunkSize
+	MaxStatusContentCacheSize, ContentCacheChunkSize = 10, 4
+	defer func() { MaxStatusContentCacheSize, ContentCacheChunkSize = maxSize, chunkSize }()
+
+	ctx := context.Background()
+	k8sClient := fake.NewClientBuilder().Build()
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "grafana", UID: "uid"},
+	}
+	gvk := v1beta1.GroupVersion.WithKind("GrafanaDashboard")
+
+	// content below the limit stays in the status
+	dashboard.Status.ContentCache = []byte("small")
+	assert.NoError(t, StoreContentCache(ctx, k8sClient, dashboard, gvk, &dashboard.Status))
+	assert.Equal(t, []byte("small"), dashboard.Status.ContentCache)
+	assert.Nil(t, dashboard.Status.ContentCacheRef)
+
+	// larger content is split into config maps owned by the dashboard
+	content := bytes.Repeat([]byte("0123456789"), 2)
+	dashboard.Status.ContentCache = content
+	assert.NoError(t, StoreContentCache(ctx, k8sClient, dashboard, gvk, &dashboard.Status))
+	assert.Nil(t, dashboard.Status.ContentCache)
+	assert.Equal(t, []string{
+		"grafanadashboard-large-content-0",
+		"grafanadashboard-large-content-1",
+		"grafanadashboard-large-content-2",
+		"grafanadashboard-large-content-3",
+		"grafanadashboard-large-content-4",
+	}, dashboard.Status.ContentCacheRef.ConfigMaps)
+
+	configMap := &v1.ConfigMap{}
+	assert.NoError(t, k8sClient.Get(ctx, client.ObjectKey{Namespace: "grafana", Name: "grafanadashboard-large-content-0"}, configMap))
+	assert.Equal(t, "GrafanaDashboard", configMap.OwnerReferences[0].Kind)
+
+	assert.NoError(t, LoadContentCache(ctx, k8sClient, "grafana", &dashboard.Status))
+	assert.Equal(t, content, dashboard.Status.ContentCache)
+
+	// shrinking content removes the chunks that are no longer needed
+	dashboard.Status.ContentCache = bytes.Repeat([]byte("abcdef"), 2)
+	assert.NoError(t, StoreContentCache(ctx, k8sClient, dashboard, gvk, &dashboard.Status))
+	assert.Len(t, dashboard.Status.ContentCacheRef.ConfigMaps, 3)
+	err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "grafana", Name: "grafanadashboard-large-content-3"}, configMap)
+	assert.True(t, errors.IsNotFound(err))
+
+	// outdated chunks are treated as an expired cache
+	configMap = &v1.ConfigMap{}
+	assert.NoError(t, k8sClient.Get(ctx, client.ObjectKey{Namespace: "grafana", Name: "grafanadashboard-large-content-0"}, configMap))
+	configMap.BinaryData[contentCacheKey] = []byte("xxxx")
+	assert.NoError(t, k8sClient.Update(ctx, configMap))
+	assert.NoError(t, LoadContentCache(ctx, k8sClient, "grafana", &dashboard.Status))
+	assert.Nil(t, dashboard.Status.ContentCache)
+}
+
+func TestContentCacheLongOwnerName(t *testing.T) {
+	maxSize, chunkSize := MaxStatusContentCacheSize, ContentCacheChunkSize
+	MaxStatusContentCacheSize, ContentCacheChunkSize = 10, 8
+	defer func() { MaxStatusContentCacheSize, ContentCacheChunkSize = maxSize, chunkSize }()
+
+	ctx := context.Background()
+	k8sClient := fake.NewClientBuilder().Build()
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 250), Namespace: "grafana", UID: "uid"},
+	}
+	gvk := v1beta1.GroupVersion.WithKind("GrafanaDashboard")
+
+	content := bytes.Repeat([]byte("0123456789"), 2)
+	dashboard.Status.ContentCache = content
+	assert.NoError(t, StoreContentCache(ctx, k8sClient, dashboard, gvk, &dashboard.Status))
+
+	names := dashboard.Status.ContentCacheRef.ConfigMaps
+	assert.Len(t, names, 3)
+	assert.NotEqual(t, names[0], names[1])
+	for _, name := range names {
+		assert.Empty(t, validation.IsDNS1123Subdomain(name))
+	}
+
+	assert.NoError(t, LoadContentCache(ctx, k8sClient, "grafana", &dashboard.Status))
+	assert.Equal(t, content, dashboard.Status.ContentCache)
+}
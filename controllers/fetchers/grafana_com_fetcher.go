@@ -0,0 +1,82 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+)
+
+// GrafanaComBaseUrl is the base url of the grafana.com api, it can be changed to point at a mirror or a test server
+var GrafanaComBaseUrl = "https://grafana.com"
+
+type grafanaComDashboard struct {
+	Revision int `json:"revision"`
+}
+
+func FetchDashboardFromGrafanaCom(ctx context.Context, dashboard *v1beta1.GrafanaDashboard) ([]byte, error) {
+	ref := dashboard.Spec.GrafanaCom
+	if ref == nil {
+		return nil, fmt.Errorf("no grafana.com reference found for dashboard %v", dashboard.Name)
+	}
+
+	// pinned revisions are cached by their download url, the latest revision by the url used to resolve it
+	dashboardUrl := fmt.Sprintf("%v/api/dashboards/%d", GrafanaComBaseUrl, ref.Id)
+	cacheUrl := dashboardUrl
+	if ref.Revision != nil {
+		cacheUrl = getGrafanaComDownloadUrl(ref.Id, *ref.Revision)
+	}
+
+	cache := dashboard.GetContentCacheForUrl(cacheUrl)
+	if len(cache) > 0 {
+		return cache, nil
+	}
+
+	var revision int
+	if ref.Revision != nil {
+		revision = *ref.Revision
+	} else {
+		latest, err := getLatestGrafanaComRevision(ctx, dashboard, dashboardUrl)
+		if err != nil {
+			return nil, err
+		}
+		revision = latest
+	}
+
+	content, err := fetchUrl(ctx, dashboard, getGrafanaComDownloadUrl(ref.Id, revision), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setContentCache(dashboard, cacheUrl, content)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard.Status.GrafanaComRevision = revision
+	return content, nil
+}
+
+func getGrafanaComDownloadUrl(id int, revision int) string {
+	return fmt.Sprintf("%v/api/dashboards/%d/revisions/%d/download", GrafanaComBaseUrl, id, revision)
+}
+
+func getLatestGrafanaComRevision(ctx context.Context, dashboard *v1beta1.GrafanaDashboard, dashboardUrl string) (int, error) {
+	content, err := fetchUrl(ctx, dashboard, dashboardUrl, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var info grafanaComDashboard
+	err = json.Unmarshal(content, &info)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse grafana.com dashboard info for dashboard %v: %w", dashboard.Name, err)
+	}
+
+	if info.Revision <= 0 {
+		return 0, fmt.Errorf("no revision found for grafana.com dashboard %v", dashboard.Spec.GrafanaCom.Id)
+	}
+
+	return info.Revision, nil
+}
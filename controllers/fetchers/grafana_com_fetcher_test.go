@@ -0,0 +1,87 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchDashboardFromGrafanaCom(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests += 1
+		w.Header().Set("Content-Type", "application/json")
+
+		var err error
+		switch r.URL.Path {
+		case "/api/dashboards/1860":
+			_, err = w.Write([]byte(`{"id": 1860, "revision": 3}`))
+		case "/api/dashboards/1860/revisions/2/download", "/api/dashboards/1860/revisions/3/download":
+			_, err = w.Write([]byte(fmt.Sprintf(`{"path": "%v"}`, r.URL.Path)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	baseUrl := GrafanaComBaseUrl
+	GrafanaComBaseUrl = ts.URL
+	defer func() {
+		GrafanaComBaseUrl = baseUrl
+	}()
+
+	t.Run("latest revision", func(t *testing.T) {
+		requests = 0
+		dashboard := &v1beta1.GrafanaDashboard{
+			Spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaCom: &v1beta1.GrafanaComDashboardReference{Id: 1860},
+			},
+		}
+
+		content, err := FetchDashboardFromGrafanaCom(context.Background(), dashboard)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`{"path": "/api/dashboards/1860/revisions/3/download"}`), content)
+		assert.Equal(t, 3, dashboard.Status.GrafanaComRevision)
+		assert.Equal(t, ts.URL+"/api/dashboards/1860", dashboard.Status.ContentUrl)
+		assert.Equal(t, 2, requests)
+
+		// served from the content cache
+		content, err = FetchDashboardFromGrafanaCom(context.Background(), dashboard)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`{"path": "/api/dashboards/1860/revisions/3/download"}`), content)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("pinned revision", func(t *testing.T) {
+		requests = 0
+		revision := 2
+		dashboard := &v1beta1.GrafanaDashboard{
+			Spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaCom: &v1beta1.GrafanaComDashboardReference{Id: 1860, Revision: &revision},
+			},
+		}
+
+		content, err := FetchDashboardFromGrafanaCom(context.Background(), dashboard)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`{"path": "/api/dashboards/1860/revisions/2/download"}`), content)
+		assert.Equal(t, 2, dashboard.Status.GrafanaComRevision)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("unknown dashboard", func(t *testing.T) {
+		dashboard := &v1beta1.GrafanaDashboard{
+			Spec: v1beta1.GrafanaDashboardSpec{
+				GrafanaCom: &v1beta1.GrafanaComDashboardReference{Id: 1},
+			},
+		}
+
+		_, err := FetchDashboardFromGrafanaCom(context.Background(), dashboard)
+		assert.Error(t, err)
+	})
+}
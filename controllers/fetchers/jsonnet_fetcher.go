@@ -2,14 +2,17 @@ package fetchers
 
 import (
 	"embed"
+	"encoding/json"
 	"fmt"
 	"io/fs"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/google/go-jsonnet"
 	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
 )
 
 // EmbedFSImporter "imports" data from an in-memory embedFS.
@@ -84,14 +87,112 @@ func (importer *EmbedFSImporter) Import(importedFrom, importedPath string) (cont
 	return foundContents, s, nil
 }
 
-func FetchJsonnet(dashboard *v1beta1.GrafanaDashboard, libsonnet embed.FS) ([]byte, error) {
+// ConfigMapImporter "imports" data from the keys of a list of config maps. Every key holds one file, imports are
+// matched against the key by their full path first and by their file name second. Imports that can't be found in
+// any config map are delegated to the fallback importer.
+type ConfigMapImporter struct {
+	ConfigMaps []v1.ConfigMap
+	Fallback   jsonnet.Importer
+}
+
+// Import fetches data from the config maps or the fallback importer.
+func (importer *ConfigMapImporter) Import(importedFrom, importedPath string) (contents jsonnet.Contents, foundAt string, err error) {
+	for _, key := range []string{importedPath, path.Base(importedPath)} {
+		for _, configMap := range importer.ConfigMaps {
+			location := fmt.Sprintf("configmap/%v/%v/%v", configMap.Namespace, configMap.Name, key)
+			if val, ok := configMap.Data[key]; ok {
+				return jsonnet.MakeContents(val), location, nil
+			}
+			if val, ok := configMap.BinaryData[key]; ok {
+				return jsonnet.MakeContentsRaw(val), location, nil
+			}
+		}
+	}
+
+	if importer.Fallback == nil {
+		return jsonnet.Contents{}, "", fmt.Errorf("import not found: %v", importedPath)
+	}
+	return importer.Fallback.Import(importedFrom, importedPath)
+}
+
+// names of the external variables the operator passes to every jsonnet dashboard
+const (
+	JsonnetExtVarDashboardNamespace = "dashboardNamespace"
+	JsonnetExtVarDashboardName      = "dashboardName"
+	JsonnetExtVarGrafanaName        = "grafanaName"
+	JsonnetExtVarGrafanaLabels      = "grafanaLabels"
+)
+
+// JsonnetVariable is an external variable or top-level argument with its value already resolved
+type JsonnetVariable struct {
+	Name  string
+	Value string
+	Code  bool
+}
+
+// JsonnetEnvironment holds everything apart from the source that is needed to evaluate a jsonnet dashboard
+type JsonnetEnvironment struct {
+	// the embedded grafonnet libraries
+	Libsonnet embed.FS
+	// config maps selected as jsonnet libraries
+	Libraries []v1.ConfigMap
+	ExtVars   []JsonnetVariable
+	TLAs      []JsonnetVariable
+}
+
+// GetJsonnetContextVariables returns the external variables describing the dashboard and the instance it is
+// evaluated for
+func GetJsonnetContextVariables(dashboard *v1beta1.GrafanaDashboard, grafana *v1beta1.Grafana) ([]JsonnetVariable, error) {
+	grafanaLabels := map[string]string{}
+	for key, value := range grafana.Labels {
+		grafanaLabels[key] = value
+	}
+
+	labels, err := json.Marshal(grafanaLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	return []JsonnetVariable{
+		{Name: JsonnetExtVarDashboardNamespace, Value: dashboard.Namespace},
+		{Name: JsonnetExtVarDashboardName, Value: dashboard.Name},
+		{Name: JsonnetExtVarGrafanaName, Value: grafana.Name},
+		{Name: JsonnetExtVarGrafanaLabels, Value: string(labels), Code: true},
+	}, nil
+}
+
+func FetchJsonnet(dashboard *v1beta1.GrafanaDashboard, env JsonnetEnvironment) ([]byte, error) {
 	if dashboard.Spec.Jsonnet == "" {
 		return nil, fmt.Errorf("no jsonnet Content Found, nil or empty string")
 	}
 	vm := jsonnet.MakeVM()
 
-	vm.Importer(&EmbedFSImporter{Embed: libsonnet})
+	vm.Importer(&ConfigMapImporter{
+		ConfigMaps: env.Libraries,
+		Fallback:   &EmbedFSImporter{Embed: env.Libsonnet},
+	})
+
+	setJsonnetVariables(vm, env)
 
 	jsonString, err := vm.EvaluateAnonymousSnippet(dashboard.Name, dashboard.Spec.Jsonnet)
 	return []byte(jsonString), err
 }
+
+// setJsonnetVariables passes the external variables and top-level arguments of the environment to the vm
+func setJsonnetVariables(vm *jsonnet.VM, env JsonnetEnvironment) {
+	for _, variable := range env.ExtVars {
+		if variable.Code {
+			vm.ExtCode(variable.Name, variable.Value)
+		} else {
+			vm.ExtVar(variable.Name, variable.Value)
+		}
+	}
+
+	for _, variable := range env.TLAs {
+		if variable.Code {
+			vm.TLACode(variable.Name, variable.Value)
+		} else {
+			vm.TLAVar(variable.Name, variable.Value)
+		}
+	}
+}
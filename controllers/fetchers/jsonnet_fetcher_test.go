@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
 	"github.com/grafana-operator/grafana-operator-experimental/embeds"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -76,7 +77,7 @@ func TestFetchJsonnet(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, err := FetchJsonnet(test.dashboard, test.libsonnet)
+			result, err := FetchJsonnet(test.dashboard, JsonnetEnvironment{Libsonnet: test.libsonnet})
 
 			if fmt.Sprintf("%v", err) != fmt.Sprintf("%v", test.expectedError) {
 				t.Errorf("expected error %v, but got %v", test.expectedError, err)
@@ -88,3 +89,84 @@ func TestFetchJsonnet(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchJsonnetWithLibraries(t *testing.T) {
+	libraries := []v1.ConfigMap{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "grafana"},
+			Data: map[string]string{
+				"helpers.libsonnet": `{ title(name):: "Team " + name }`,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "team", Namespace: "grafana"},
+			BinaryData: map[string][]byte{
+				"panels.libsonnet": []byte(`{ panels: [{ type: "text" }] }`),
+			},
+		},
+	}
+
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "grafana"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			Jsonnet: `
+local helpers = import 'helpers.libsonnet';
+local panels = import 'lib/panels.libsonnet';
+{ title: helpers.title('a'), panels: panels.panels }`,
+		},
+	}
+
+	result, err := FetchJsonnet(dashboard, JsonnetEnvironment{Libsonnet: embeds.GrafonnetEmbed, Libraries: libraries})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte(`{"title": "Team a", "panels": [{"type": "text"}]}`)
+	if !normalizeAndCompareJson(expected, result) {
+		t.Errorf("expected string %s, but got %s", string(expected), string(result))
+	}
+}
+
+func TestFetchJsonnetWithVariables(t *testing.T) {
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "grafana"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			Jsonnet: `
+function(cluster, replicas) {
+  title: std.extVar('dashboardNamespace') + '/' + std.extVar('dashboardName') + ' on ' + std.extVar('grafanaName'),
+  tags: [std.extVar('grafanaLabels').env, std.extVar('region'), cluster],
+  replicas: replicas,
+}`,
+		},
+	}
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "grafana",
+			Labels: map[string]string{"env": "prod"},
+		},
+	}
+
+	extVars, err := GetJsonnetContextVariables(dashboard, grafana)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := JsonnetEnvironment{
+		Libsonnet: embeds.GrafonnetEmbed,
+		ExtVars:   append(extVars, JsonnetVariable{Name: "region", Value: "eu"}),
+		TLAs: []JsonnetVariable{
+			{Name: "cluster", Value: "prod-1"},
+			{Name: "replicas", Value: "1 + 2", Code: true},
+		},
+	}
+
+	result, err := FetchJsonnet(dashboard, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte(`{"title": "grafana/dashboard on grafana", "tags": ["prod", "eu", "prod-1"], "replicas": 3}`)
+	if !normalizeAndCompareJson(expected, result) {
+		t.Errorf("expected string %s, but got %s", string(expected), string(result))
+	}
+}
@@ -0,0 +1,170 @@
+package fetchers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/google/go-jsonnet"
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ArchiveImporter "imports" data from the files of an unpacked archive. Imports are resolved relative to the
+// importing file first and relative to the root of the archive second. Imports that can't be found in the archive
+// are delegated to the fallback importer.
+type ArchiveImporter struct {
+	Files    map[string][]byte
+	Fallback jsonnet.Importer
+}
+
+// Import fetches data from the archive or the fallback importer.
+func (importer *ArchiveImporter) Import(importedFrom, importedPath string) (contents jsonnet.Contents, foundAt string, err error) {
+	candidates := []string{path.Clean(strings.TrimPrefix(importedPath, "/"))}
+	if !path.IsAbs(importedPath) && importedFrom != "" {
+		candidates = append([]string{path.Join(path.Dir(importedFrom), importedPath)}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		if content, ok := importer.Files[candidate]; ok {
+			return jsonnet.MakeContentsRaw(content), candidate, nil
+		}
+	}
+
+	if importer.Fallback == nil {
+		return jsonnet.Contents{}, "", fmt.Errorf("import not found: %v", importedPath)
+	}
+	return importer.Fallback.Import(importedFrom, importedPath)
+}
+
+// FetchJsonnetProject evaluates the entrypoint of a jsonnet project packaged as a gzipped tarball
+func FetchJsonnetProject(ctx context.Context, c client.Client, dashboard *v1beta1.GrafanaDashboard, env JsonnetEnvironment) ([]byte, error) {
+	project := dashboard.Spec.JsonnetProject
+	if project == nil {
+		return nil, fmt.Errorf("no jsonnet project found for dashboard %v", dashboard.Name)
+	}
+
+	archive, err := getJsonnetProjectArchive(ctx, c, dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := unpackTarball(archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack jsonnet project of dashboard %v: %v", dashboard.Name, err)
+	}
+
+	entrypoint := path.Clean(strings.TrimPrefix(project.Entrypoint, "/"))
+	if _, ok := files[entrypoint]; !ok {
+		return nil, fmt.Errorf("entrypoint %v not found in jsonnet project of dashboard %v", project.Entrypoint, dashboard.Name)
+	}
+
+	vm := jsonnet.MakeVM()
+
+	vm.Importer(&ArchiveImporter{
+		Files: files,
+		Fallback: &ConfigMapImporter{
+			ConfigMaps: env.Libraries,
+			Fallback:   &EmbedFSImporter{Embed: env.Libsonnet},
+		},
+	})
+
+	setJsonnetVariables(vm, env)
+
+	// the entrypoint is loaded through the importer, so that its imports are resolved relative to its location
+	jsonString, err := vm.EvaluateFile(entrypoint)
+	return []byte(jsonString), err
+}
+
+// getJsonnetProjectArchive returns the gzipped tarball from the source configured in the dashboard
+func getJsonnetProjectArchive(ctx context.Context, c client.Client, dashboard *v1beta1.GrafanaDashboard) ([]byte, error) {
+	project := dashboard.Spec.JsonnetProject
+
+	switch {
+	case project.GzipTar != nil:
+		return project.GzipTar, nil
+	case project.ConfigMapRef != nil:
+		configMap := &v1.ConfigMap{}
+		selector := client.ObjectKey{
+			Namespace: dashboard.Namespace,
+			Name:      project.ConfigMapRef.Name,
+		}
+
+		err := c.Get(ctx, selector, configMap)
+		if err != nil {
+			return nil, err
+		}
+
+		if val, ok := configMap.BinaryData[project.ConfigMapRef.Key]; ok {
+			return val, nil
+		}
+
+		if val, ok := configMap.Data[project.ConfigMapRef.Key]; ok {
+			return []byte(val), nil
+		}
+
+		return nil, fmt.Errorf("key %v not found in config map %v/%v", project.ConfigMapRef.Key, dashboard.Namespace, project.ConfigMapRef.Name)
+	case project.Url != "":
+		return fetchCachedUrl(ctx, c, dashboard, project.Url)
+	default:
+		return nil, fmt.Errorf("no source for the jsonnet project of dashboard %v", dashboard.Name)
+	}
+}
+
+// unpackTarball reads all regular files of a gzipped tarball into memory, keyed by their cleaned path. The unpacked
+// size is limited to MaxUrlResponseSize, so that small archives can't expand into huge amounts of memory.
+func unpackTarball(archive []byte) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	unpacked := &io.LimitedReader{R: gz, N: MaxUrlResponseSize + 1}
+	exceeded := func() bool {
+		return MaxUrlResponseSize > 0 && unpacked.N <= 0
+	}
+
+	var source io.Reader = gz
+	if MaxUrlResponseSize > 0 {
+		source = unpacked
+	}
+
+	files := map[string][]byte{}
+	reader := tar.NewReader(source)
+	for {
+		header, err := reader.Next()
+		if exceeded() {
+			return nil, fmt.Errorf("unpacked archive exceeds the maximum size of %v bytes", MaxUrlResponseSize)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		content, err := io.ReadAll(reader)
+		if exceeded() {
+			return nil, fmt.Errorf("unpacked archive exceeds the maximum size of %v bytes", MaxUrlResponseSize)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		files[path.Clean(strings.TrimPrefix(header.Name, "/"))] = content
+	}
+
+	return files, nil
+}
@@ -0,0 +1,118 @@
+package fetchers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/grafana-operator/grafana-operator-experimental/embeds"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func makeTarball(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	writer := tar.NewWriter(gz)
+	for name, content := range files {
+		err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		assert.NoError(t, err)
+		_, err = writer.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestFetchJsonnetProject(t *testing.T) {
+	archive := makeTarball(t, map[string]string{
+		"./dashboards/main.jsonnet": `
+local panels = import '../lib/panels.libsonnet';
+local helpers = import 'helpers.libsonnet';
+function(title) { title: helpers.title(title), panels: panels.panels }`,
+		"lib/panels.libsonnet": `{ panels: [import 'text.libsonnet'] }`,
+		"lib/text.libsonnet":   `{ type: "text" }`,
+	})
+
+	libraries := []v1.ConfigMap{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "grafana"},
+			Data: map[string]string{
+				"helpers.libsonnet": `{ title(name):: "Team " + name }`,
+			},
+		},
+	}
+
+	env := JsonnetEnvironment{
+		Libsonnet: embeds.GrafonnetEmbed,
+		Libraries: libraries,
+		TLAs:      []JsonnetVariable{{Name: "title", Value: "a"}},
+	}
+
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "project", Namespace: "grafana"},
+			BinaryData: map[string][]byte{"project.tar.gz": archive},
+		},
+	).Build()
+
+	dashboard := func(project *v1beta1.JsonnetProject) *v1beta1.GrafanaDashboard {
+		return &v1beta1.GrafanaDashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "grafana"},
+			Spec:       v1beta1.GrafanaDashboardSpec{JsonnetProject: project},
+		}
+	}
+
+	expected := []byte(`{"title": "Team a", "panels": [{"type": "text"}]}`)
+
+	result, err := FetchJsonnetProject(context.Background(), k8sClient, dashboard(&v1beta1.JsonnetProject{
+		Entrypoint: "dashboards/main.jsonnet",
+		GzipTar:    archive,
+	}), env)
+	assert.NoError(t, err)
+	assert.True(t, normalizeAndCompareJson(expected, result), string(result))
+
+	result, err = FetchJsonnetProject(context.Background(), k8sClient, dashboard(&v1beta1.JsonnetProject{
+		Entrypoint: "dashboards/main.jsonnet",
+		ConfigMapRef: &v1.ConfigMapKeySelector{
+			LocalObjectReference: v1.LocalObjectReference{Name: "project"},
+			Key:                  "project.tar.gz",
+		},
+	}), env)
+	assert.NoError(t, err)
+	assert.True(t, normalizeAndCompareJson(expected, result), string(result))
+
+	_, err = FetchJsonnetProject(context.Background(), k8sClient, dashboard(&v1beta1.JsonnetProject{
+		Entrypoint: "missing.jsonnet",
+		GzipTar:    archive,
+	}), env)
+	assert.Error(t, err)
+}
+
+func TestUnpackTarballMaxSize(t *testing.T) {
+	maxSize := MaxUrlResponseSize
+	MaxUrlResponseSize = 64 * 1024
+	defer func() { MaxUrlResponseSize = maxSize }()
+
+	// compresses to a fraction of the limit, but unpacks far beyond it
+	archive := makeTarball(t, map[string]string{
+		"main.jsonnet": strings.Repeat(" ", 1024*1024),
+	})
+	assert.Less(t, int64(len(archive)), MaxUrlResponseSize)
+
+	_, err := unpackTarball(archive)
+	assert.ErrorContains(t, err, "exceeds the maximum size")
+
+	files, err := unpackTarball(makeTarball(t, map[string]string{
+		"main.jsonnet": "{}",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("{}"), files["main.jsonnet"])
+}
@@ -0,0 +1,35 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FetchDashboardFromSecret reads the dashboard json from a key of a secret in the dashboard's namespace
+func FetchDashboardFromSecret(ctx context.Context, c client.Client, dashboard *v1beta1.GrafanaDashboard) ([]byte, error) {
+	ref := dashboard.Spec.SecretRef
+	if ref == nil {
+		return nil, fmt.Errorf("no secret reference found for dashboard %v", dashboard.Name)
+	}
+
+	secret := &v1.Secret{}
+	selector := client.ObjectKey{
+		Namespace: dashboard.Namespace,
+		Name:      ref.Name,
+	}
+
+	err := c.Get(ctx, selector, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if val, ok := secret.Data[ref.Key]; ok {
+		return val, nil
+	}
+
+	return nil, fmt.Errorf("key %v not found in secret %v/%v", ref.Key, dashboard.Namespace, ref.Name)
+}
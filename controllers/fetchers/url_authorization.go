@@ -0,0 +1,141 @@
+package fetchers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// urlRequestOptions are applied to the requests against urls configured in a dashboard
+type urlRequestOptions struct {
+	header   http.Header
+	caBundle []byte
+}
+
+// getUrlRequestOptions resolves the credentials and the ca bundle referenced in the dashboard
+func getUrlRequestOptions(ctx context.Context, c client.Client, dashboard *v1beta1.GrafanaDashboard) (*urlRequestOptions, error) {
+	options := &urlRequestOptions{
+		header: http.Header{},
+	}
+
+	getValueFromSecret := func(ref *v1.SecretKeySelector) (string, error) {
+		if ref == nil {
+			return "", fmt.Errorf("missing secret reference in url authorization of dashboard %v", dashboard.Name)
+		}
+
+		secret := &v1.Secret{}
+		selector := client.ObjectKey{
+			Name:      ref.Name,
+			Namespace: dashboard.Namespace,
+		}
+		err := c.Get(ctx, selector, secret)
+		if err != nil {
+			return "", err
+		}
+
+		if val, ok := secret.Data[ref.Key]; ok {
+			return string(val), nil
+		}
+
+		return "", fmt.Errorf("key %v not found in secret %v/%v", ref.Key, dashboard.Namespace, ref.Name)
+	}
+
+	if auth := dashboard.Spec.UrlAuthorization; auth != nil {
+		for _, header := range auth.Headers {
+			value, err := getValueFromSecret(header.ValueFrom)
+			if err != nil {
+				return nil, err
+			}
+			options.header.Set(header.Name, value)
+		}
+
+		if auth.BasicAuth != nil {
+			username, err := getValueFromSecret(auth.BasicAuth.Username)
+			if err != nil {
+				return nil, err
+			}
+			password, err := getValueFromSecret(auth.BasicAuth.Password)
+			if err != nil {
+				return nil, err
+			}
+			credentials := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%v:%v", username, password)))
+			options.header.Set("Authorization", fmt.Sprintf("Basic %v", credentials))
+		}
+
+		if auth.BearerToken != nil {
+			token, err := getValueFromSecret(auth.BearerToken)
+			if err != nil {
+				return nil, err
+			}
+			options.header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
+		}
+	}
+
+	if bundle := dashboard.Spec.UrlCaBundle; bundle != nil {
+		switch {
+		case bundle.ConfigMapKeyRef != nil:
+			configMap := &v1.ConfigMap{}
+			selector := client.ObjectKey{
+				Name:      bundle.ConfigMapKeyRef.Name,
+				Namespace: dashboard.Namespace,
+			}
+			err := c.Get(ctx, selector, configMap)
+			if err != nil {
+				return nil, err
+			}
+
+			val, ok := configMap.Data[bundle.ConfigMapKeyRef.Key]
+			if !ok {
+				return nil, fmt.Errorf("key %v not found in config map %v/%v", bundle.ConfigMapKeyRef.Key, dashboard.Namespace, bundle.ConfigMapKeyRef.Name)
+			}
+			options.caBundle = []byte(val)
+		case bundle.SecretKeyRef != nil:
+			val, err := getValueFromSecret(bundle.SecretKeyRef)
+			if err != nil {
+				return nil, err
+			}
+			options.caBundle = []byte(val)
+		}
+	}
+
+	return options, nil
+}
+
+// getTLSKey identifies the tls config of the options, requests with the same ca bundle share their connections
+func (in *urlRequestOptions) getTLSKey() string {
+	if in == nil || len(in.caBundle) == 0 {
+		return "system"
+	}
+	return fmt.Sprintf("ca-%x", sha256.Sum256(in.caBundle))
+}
+
+// getTLSConfig returns a tls config verifying servers against the ca bundle, or the system roots if no bundle is set
+func (in *urlRequestOptions) getTLSConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if in == nil || len(in.caBundle) == 0 {
+		return config, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(in.caBundle) {
+		return nil, fmt.Errorf("no valid certificates found in ca bundle")
+	}
+
+	config.RootCAs = pool
+	return config, nil
+}
@@ -1,6 +1,9 @@
 package fetchers
 
 import (
+	"context"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,48 +15,212 @@ import (
 	"github.com/grafana-operator/grafana-operator-experimental/controllers/metrics"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-func FetchDashboardFromUrl(dashboard *v1beta1.GrafanaDashboard) ([]byte, error) {
-	url, err := url.Parse(dashboard.Spec.Url)
+// MaxUrlResponseSize is the maximum size in bytes of a response body read from a url, larger responses are rejected
+var MaxUrlResponseSize int64 = 10 * 1024 * 1024
+
+// UrlRequestTimeout is the maximum duration of a single request against a url, including reading the response body
+var UrlRequestTimeout = 30 * time.Second
+
+// UrlRetryBackoff controls how often and how fast requests against urls are retried after transient failures
+var UrlRetryBackoff = wait.Backoff{
+	Steps:    3,
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+}
+
+// transientUrlError is returned for failures that may go away when the request is retried
+type transientUrlError struct {
+	error
+}
+
+// urlResponse is the result of a request against a url
+type urlResponse struct {
+	content      []byte
+	etag         string
+	lastModified string
+	notModified  bool
+}
+
+func FetchDashboardFromUrl(ctx context.Context, c client.Client, dashboard *v1beta1.GrafanaDashboard) ([]byte, error) {
+	_, err := url.Parse(dashboard.Spec.Url)
 	if err != nil {
 		return nil, err
 	}
 
-	cache := dashboard.GetContentCache()
+	return fetchCachedUrl(ctx, c, dashboard, dashboard.Spec.Url)
+}
+
+// fetchCachedUrl returns the content of the url from the content cache while it is fresh. An expired cache is
+// revalidated with a conditional request and returned as is when the url can't be reached.
+func fetchCachedUrl(ctx context.Context, c client.Client, dashboard *v1beta1.GrafanaDashboard, url string) ([]byte, error) {
+	cache := dashboard.GetContentCacheForUrl(url)
 	if len(cache) > 0 {
 		return cache, nil
 	}
 
-	request, err := http.NewRequest(http.MethodGet, url.String(), nil)
+	options, err := getUrlRequestOptions(ctx, c, dashboard)
 	if err != nil {
 		return nil, err
 	}
 
-	client := client2.NewInstrumentedRoundTripper(fmt.Sprintf("%v/%v", dashboard.Namespace, dashboard.Name), metrics.DashboardUrlRequests)
-	response, err := client.RoundTrip(request)
+	stale := dashboard.GetStaleContentCacheForUrl(url)
+	response, err := requestUrl(ctx, dashboard, url, options, len(stale) > 0)
 	if err != nil {
+		var transient transientUrlError
+		if len(stale) > 0 && errors.As(err, &transient) {
+			log.FromContext(ctx).Error(err, "failed to fetch dashboard url, using stale content cache", "url", url)
+			return stale, nil
+		}
 		return nil, err
 	}
+
+	if response.notModified {
+		dashboard.Status.ContentTimestamp = v1.Time{Time: time.Now()}
+		return stale, nil
+	}
+
+	err = setContentCache(dashboard, url, response.content)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.Status.ContentEtag = response.etag
+	dashboard.Status.ContentLastModified = response.lastModified
+
+	return response.content, nil
+}
+
+// fetchUrl issues a get request against the url and returns the response body, options may be nil
+func fetchUrl(ctx context.Context, dashboard *v1beta1.GrafanaDashboard, url string, options *urlRequestOptions) ([]byte, error) {
+	response, err := requestUrl(ctx, dashboard, url, options, false)
+	if err != nil {
+		return nil, err
+	}
+	return response.content, nil
+}
+
+// requestUrl issues a get request against the url and retries transient failures with an exponential backoff. A
+// conditional request is sent when the content cache holds validators for the url. Retries stop when ctx is done.
+func requestUrl(ctx context.Context, dashboard *v1beta1.GrafanaDashboard, url string, options *urlRequestOptions, conditional bool) (*urlResponse, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if options != nil {
+		for key, values := range options.header {
+			request.Header[key] = values
+		}
+	}
+
+	if conditional && dashboard.Status.ContentUrl == url {
+		if dashboard.Status.ContentEtag != "" {
+			request.Header.Set("If-None-Match", dashboard.Status.ContentEtag)
+		}
+		if dashboard.Status.ContentLastModified != "" {
+			request.Header.Set("If-Modified-Since", dashboard.Status.ContentLastModified)
+		}
+	}
+
+	tlsConfig, err := options.getTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid ca bundle for dashboard %v: %v", dashboard.Name, err)
+	}
+
+	client := client2.NewInstrumentedRoundTripperWithTLSConfig(fmt.Sprintf("%v/%v", dashboard.Namespace, dashboard.Name), metrics.DashboardUrlRequests, options.getTLSKey(), tlsConfig)
+
+	backoff := UrlRetryBackoff
+	for {
+		response, err := doUrlRequest(client, request, dashboard)
+		var transient transientUrlError
+		if err == nil || !errors.As(err, &transient) || backoff.Steps <= 1 {
+			return response, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// doUrlRequest sends a single request and reads the response body up to the maximum response size, the request is
+// cancelled after UrlRequestTimeout
+func doUrlRequest(client http.RoundTripper, request *http.Request, dashboard *v1beta1.GrafanaDashboard) (*urlResponse, error) {
+	if UrlRequestTimeout > 0 {
+		ctx, cancel := context.WithTimeout(request.Context(), UrlRequestTimeout)
+		defer cancel()
+		request = request.WithContext(ctx)
+	}
+
+	response, err := client.RoundTrip(request)
+	if err != nil {
+		// certificate errors won't go away by retrying
+		var unknownAuthority x509.UnknownAuthorityError
+		var invalidHostname x509.HostnameError
+		var invalidCertificate x509.CertificateInvalidError
+		if errors.As(err, &unknownAuthority) || errors.As(err, &invalidHostname) || errors.As(err, &invalidCertificate) {
+			return nil, err
+		}
+		return nil, transientUrlError{err}
+	}
 	defer response.Body.Close()
 
+	if response.StatusCode == http.StatusNotModified {
+		return &urlResponse{notModified: true}, nil
+	}
+
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code from dashboard url request, get %v for dashboard %v", response.StatusCode, dashboard.Name)
+		err := fmt.Errorf("unexpected status code from dashboard url request, get %v for dashboard %v", response.StatusCode, dashboard.Name)
+		if response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusTooManyRequests {
+			return nil, transientUrlError{err}
+		}
+		return nil, err
+	}
+
+	if MaxUrlResponseSize > 0 && response.ContentLength > MaxUrlResponseSize {
+		return nil, fmt.Errorf("dashboard url response of %v bytes exceeds the maximum size of %v bytes for dashboard %v", response.ContentLength, MaxUrlResponseSize, dashboard.Name)
+	}
+
+	body := io.Reader(response.Body)
+	if MaxUrlResponseSize > 0 {
+		body = io.LimitReader(response.Body, MaxUrlResponseSize+1)
 	}
 
-	content, err := io.ReadAll(response.Body)
+	content, err := io.ReadAll(body)
 	if err != nil {
-		return []byte{}, err
+		return nil, transientUrlError{err}
 	}
 
+	if MaxUrlResponseSize > 0 && int64(len(content)) > MaxUrlResponseSize {
+		return nil, fmt.Errorf("dashboard url response exceeds the maximum size of %v bytes for dashboard %v", MaxUrlResponseSize, dashboard.Name)
+	}
+
+	return &urlResponse{
+		content:      content,
+		etag:         response.Header.Get("ETag"),
+		lastModified: response.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// setContentCache stores the gzipped content in the dashboard status, the url is used to invalidate the cache when the source changes
+func setContentCache(dashboard *v1beta1.GrafanaDashboard, url string, content []byte) error {
 	gz, err := v1beta1.Gzip(content)
 	if err != nil {
-		return []byte{}, fmt.Errorf("failed to gzip dashboard %v", dashboard.Name)
+		return fmt.Errorf("failed to gzip dashboard %v", dashboard.Name)
 	}
 
 	dashboard.Status.ContentCache = gz
 	dashboard.Status.ContentTimestamp = v1.Time{Time: time.Now()}
-	dashboard.Status.ContentUrl = dashboard.Spec.Url
+	dashboard.Status.ContentUrl = url
+	dashboard.Status.ContentEtag = ""
+	dashboard.Status.ContentLastModified = ""
 
-	return content, nil
+	return nil
 }
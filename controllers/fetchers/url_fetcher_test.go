@@ -1,12 +1,18 @@
 package fetchers
 
 import (
+	"context"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestFetchDashboardFromUrl(t *testing.T) {
@@ -28,7 +34,7 @@ func TestFetchDashboardFromUrl(t *testing.T) {
 		Status: v1beta1.GrafanaDashboardStatus{},
 	}
 
-	fetchedDashboard, err := FetchDashboardFromUrl(dashboard)
+	fetchedDashboard, err := FetchDashboardFromUrl(context.Background(), fake.NewClientBuilder().Build(), dashboard)
 	assert.Nil(t, err)
 	assert.Equal(t, dashboardJSON, fetchedDashboard, "Fetched dashboard doesn't match the original")
 
@@ -36,3 +42,194 @@ func TestFetchDashboardFromUrl(t *testing.T) {
 	assert.Equal(t, compressedJSON, dashboard.Status.ContentCache, "ContentCache should have been updated")
 	assert.Equal(t, ts.URL, dashboard.Status.ContentUrl, "ContentUrl should have been updated")
 }
+
+func TestFetchDashboardFromUrlWithAuthorization(t *testing.T) {
+	dashboardJSON := []byte(`{"dummyField": "dummyData"}`)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "password" || r.Header.Get("X-Scope") != "dashboards" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, err := w.Write(dashboardJSON)
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "grafana"},
+			Data: map[string][]byte{
+				"username": []byte("user"),
+				"password": []byte("password"),
+				"scope":    []byte("dashboards"),
+			},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "grafana"},
+			Data:       map[string]string{"ca.crt": string(caBundle)},
+		},
+	).Build()
+
+	secretKey := func(key string) *v1.SecretKeySelector {
+		return &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "credentials"}, Key: key}
+	}
+
+	dashboard := &v1beta1.GrafanaDashboard{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboard", Namespace: "grafana"},
+		Spec: v1beta1.GrafanaDashboardSpec{
+			Url: ts.URL,
+			UrlAuthorization: &v1beta1.GrafanaDashboardUrlAuthorization{
+				BasicAuth: &v1beta1.GrafanaDashboardUrlBasicAuth{
+					Username: secretKey("username"),
+					Password: secretKey("password"),
+				},
+				Headers: []v1beta1.GrafanaDashboardUrlHeader{{Name: "X-Scope", ValueFrom: secretKey("scope")}},
+			},
+		},
+	}
+
+	// the certificate of the test server is not trusted without the ca bundle
+	_, err := FetchDashboardFromUrl(context.Background(), k8sClient, dashboard)
+	assert.Error(t, err)
+
+	dashboard.Spec.UrlCaBundle = &v1beta1.CaBundleSource{
+		ConfigMapKeyRef: &v1.ConfigMapKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: "ca"}, Key: "ca.crt"},
+	}
+
+	fetchedDashboard, err := FetchDashboardFromUrl(context.Background(), k8sClient, dashboard)
+	assert.NoError(t, err)
+	assert.Equal(t, dashboardJSON, fetchedDashboard)
+
+	dashboard.Status = v1beta1.GrafanaDashboardStatus{}
+	dashboard.Spec.UrlAuthorization.BasicAuth.Password = secretKey("missing")
+	_, err = FetchDashboardFromUrl(context.Background(), k8sClient, dashboard)
+	assert.Error(t, err)
+}
+
+func TestFetchDashboardFromUrlRevalidatesCache(t *testing.T) {
+	dashboardJSON := []byte(`{"dummyField": "dummyData"}`)
+	backoff := UrlRetryBackoff
+	UrlRetryBackoff.Duration = time.Millisecond
+	defer func() { UrlRetryBackoff = backoff }()
+
+	requests := 0
+	failing := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, err := w.Write(dashboardJSON)
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	dashboard := &v1beta1.GrafanaDashboard{
+		Spec: v1beta1.GrafanaDashboardSpec{
+			Url:                  ts.URL,
+			ContentCacheDuration: metav1.Duration{Duration: time.Minute},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().Build()
+	expire := func() {
+		dashboard.Status.ContentTimestamp = metav1.Time{Time: time.Now().Add(-time.Hour)}
+	}
+
+	content, err := FetchDashboardFromUrl(context.Background(), k8sClient, dashboard)
+	assert.NoError(t, err)
+	assert.Equal(t, dashboardJSON, content)
+	assert.Equal(t, `"v1"`, dashboard.Status.ContentEtag)
+
+	// the expired cache is revalidated instead of downloaded again
+	expire()
+	content, err = FetchDashboardFromUrl(context.Background(), k8sClient, dashboard)
+	assert.NoError(t, err)
+	assert.Equal(t, dashboardJSON, content)
+	assert.Equal(t, 2, requests)
+	assert.True(t, dashboard.Status.ContentTimestamp.After(time.Now().Add(-time.Minute)))
+
+	// transient failures are retried and fall back to the stale cache
+	expire()
+	failing = true
+	content, err = FetchDashboardFromUrl(context.Background(), k8sClient, dashboard)
+	assert.NoError(t, err)
+	assert.Equal(t, dashboardJSON, content)
+	assert.Equal(t, 2+UrlRetryBackoff.Steps, requests)
+
+	// without a cache the failure is returned
+	dashboard.Status = v1beta1.GrafanaDashboardStatus{}
+	_, err = FetchDashboardFromUrl(context.Background(), k8sClient, dashboard)
+	assert.Error(t, err)
+}
+
+func TestFetchDashboardFromUrlMaxResponseSize(t *testing.T) {
+	maxSize := MaxUrlResponseSize
+	MaxUrlResponseSize = 8
+	defer func() { MaxUrlResponseSize = maxSize }()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(`{"dummyField": "dummyData"}`))
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	dashboard := &v1beta1.GrafanaDashboard{
+		Spec: v1beta1.GrafanaDashboardSpec{
+			Url: ts.URL,
+		},
+	}
+
+	_, err := FetchDashboardFromUrl(context.Background(), fake.NewClientBuilder().Build(), dashboard)
+	assert.Error(t, err)
+	assert.Empty(t, dashboard.Status.ContentCache)
+}
+
+func TestFetchDashboardFromUrlTimeout(t *testing.T) {
+	timeout, backoff := UrlRequestTimeout, UrlRetryBackoff
+	UrlRequestTimeout = 50 * time.Millisecond
+	UrlRetryBackoff.Duration = 10 * time.Millisecond
+	defer func() { UrlRequestTimeout, UrlRetryBackoff = timeout, backoff }()
+
+	stalled := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-stalled:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(stalled)
+
+	dashboard := &v1beta1.GrafanaDashboard{
+		Spec: v1beta1.GrafanaDashboardSpec{
+			Url: ts.URL,
+		},
+	}
+
+	// every attempt is cancelled after the request timeout
+	start := time.Now()
+	_, err := FetchDashboardFromUrl(context.Background(), fake.NewClientBuilder().Build(), dashboard)
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// retries stop once the context is done
+	UrlRequestTimeout = 0
+	UrlRetryBackoff.Duration = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start = time.Now()
+	_, err = FetchDashboardFromUrl(ctx, fake.NewClientBuilder().Build(), dashboard)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
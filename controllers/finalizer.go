@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// syncedInstance is an instance a resource was synced to, together with the uid of the resource in the instance
+type syncedInstance struct {
+	grafana *v1beta1.Grafana
+	uid     string
+}
+
+// statusList returns the list in the status of an instance that tracks the resources of one type
+type statusList func(grafana *v1beta1.Grafana) *v1beta1.NamespacedResourceList
+
+func dashboardStatusList(grafana *v1beta1.Grafana) *v1beta1.NamespacedResourceList {
+	return &grafana.Status.Dashboards
+}
+
+func datasourceStatusList(grafana *v1beta1.Grafana) *v1beta1.NamespacedResourceList {
+	return &grafana.Status.Datasources
+}
+
+func folderStatusList(grafana *v1beta1.Grafana) *v1beta1.NamespacedResourceList {
+	return &grafana.Status.Folders
+}
+
+func libraryPanelStatusList(grafana *v1beta1.Grafana) *v1beta1.NamespacedResourceList {
+	return &grafana.Status.LibraryPanels
+}
+
+// reconcileFinalizer adds the finalizer to a resource that isn't being deleted. A resource that is being deleted is
+// finalized before the finalizer is removed. Returns true if the resource is being deleted.
+func reconcileFinalizer(ctx context.Context, k8sClient client.Client, cr client.Object, finalize func() error) (bool, error) {
+	if cr.GetDeletionTimestamp() == nil {
+		if controllerutil.AddFinalizer(cr, v1beta1.FinalizerName) {
+			return false, k8sClient.Update(ctx, cr)
+		}
+		return false, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(cr, v1beta1.FinalizerName) {
+		return true, nil
+	}
+
+	err := finalize()
+	if err != nil {
+		return true, err
+	}
+
+	controllerutil.RemoveFinalizer(cr, v1beta1.FinalizerName)
+	return true, k8sClient.Update(ctx, cr)
+}
+
+// getSyncedInstances returns the instances a resource was synced to. Instances are looked up in their own status and
+// in the sync status of the resource, content is found even if the status of an instance was overwritten.
+func getSyncedInstances(ctx context.Context, k8sClient client.Client, namespace string, name string, synced v1beta1.InstanceSyncStatusList, list statusList) ([]syncedInstance, error) {
+	grafanas := &v1beta1.GrafanaList{}
+	err := k8sClient.List(ctx, grafanas)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []syncedInstance
+	for i := range grafanas.Items {
+		grafana := &grafanas.Items[i]
+
+		uid := ""
+		if found, statusUid := list(grafana).Find(namespace, name); found {
+			uid = *statusUid
+		} else if status := synced.Find(fmt.Sprintf("%v/%v", grafana.Namespace, grafana.Name)); status != nil {
+			uid = status.Uid
+		}
+
+		if uid != "" {
+			instances = append(instances, syncedInstance{grafana: grafana, uid: uid})
+		}
+	}
+	return instances, nil
+}
+
+// releaseContent removes a resource from the status of all instances without deleting its content, the content is
+// no longer managed by the operator
+func releaseContent(ctx context.Context, k8sClient client.Client, namespace string, name string, list statusList) error {
+	grafanas := &v1beta1.GrafanaList{}
+	err := k8sClient.List(ctx, grafanas)
+	if err != nil {
+		return err
+	}
+
+	for i := range grafanas.Items {
+		grafana := &grafanas.Items[i]
+		if found, _ := list(grafana).Find(namespace, name); !found {
+			continue
+		}
+
+		*list(grafana) = list(grafana).Remove(namespace, name)
+		err = k8sClient.Status().Update(ctx, grafana)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func getFinalizerTestScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+	return scheme
+}
+
+func TestReconcileFinalizer(t *testing.T) {
+	folder := &v1beta1.GrafanaFolder{ObjectMeta: metav1.ObjectMeta{Name: "folder", Namespace: "default"}}
+	k8sClient := fake.NewClientBuilder().WithScheme(getFinalizerTestScheme(t)).WithObjects(folder).Build()
+
+	finalized := 0
+	finalize := func() error {
+		finalized++
+		return nil
+	}
+
+	deleting, err := reconcileFinalizer(context.Background(), k8sClient, folder, finalize)
+	assert.NoError(t, err)
+	assert.False(t, deleting)
+	assert.True(t, controllerutil.ContainsFinalizer(folder, v1beta1.FinalizerName))
+
+	// the resource stays until it is finalized
+	assert.NoError(t, k8sClient.Delete(context.Background(), folder))
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(folder), folder))
+	assert.NotNil(t, folder.DeletionTimestamp)
+
+	deleting, err = reconcileFinalizer(context.Background(), k8sClient, folder, finalize)
+	assert.NoError(t, err)
+	assert.True(t, deleting)
+	assert.Equal(t, 1, finalized)
+
+	err = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(folder), folder)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestGetSyncedInstances(t *testing.T) {
+	// a has the folder in its status, the status of b was overwritten, c never had the folder
+	grafanas := []client.Object{
+		&v1beta1.Grafana{
+			ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "grafana"},
+			Status:     v1beta1.GrafanaStatus{Folders: v1beta1.NamespacedResourceList{"default/folder/uid-a"}},
+		},
+		&v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "grafana"}},
+		&v1beta1.Grafana{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "grafana"}},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(getFinalizerTestScheme(t)).WithObjects(grafanas...).Build()
+
+	synced := v1beta1.InstanceSyncStatusList{
+		{Instance: "grafana/a", Uid: "uid-a"},
+		{Instance: "grafana/b", Uid: "uid-b"},
+	}
+
+	instances, err := getSyncedInstances(context.Background(), k8sClient, "default", "folder", synced, folderStatusList)
+	assert.NoError(t, err)
+	assert.Len(t, instances, 2)
+	assert.Equal(t, "a", instances[0].grafana.Name)
+	assert.Equal(t, "uid-a", instances[0].uid)
+	assert.Equal(t, "b", instances[1].grafana.Name)
+	assert.Equal(t, "uid-b", instances[1].uid)
+}
+
+func TestReleaseContent(t *testing.T) {
+	grafana := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "grafana"},
+		Status: v1beta1.GrafanaStatus{
+			Dashboards: v1beta1.NamespacedResourceList{"default/overview/uid-overview", "default/other/uid-other"},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(getFinalizerTestScheme(t)).WithObjects(grafana).Build()
+
+	assert.NoError(t, releaseContent(context.Background(), k8sClient, "default", "overview", dashboardStatusList))
+
+	assert.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(grafana), grafana))
+	assert.Equal(t, v1beta1.NamespacedResourceList{"default/other/uid-other"}, grafana.Status.Dashboards)
+}
@@ -18,10 +18,12 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
+	grafanaclient "github.com/grafana-operator/grafana-operator-experimental/controllers/client"
 	"github.com/grafana-operator/grafana-operator-experimental/controllers/metrics"
 	"github.com/grafana-operator/grafana-operator-experimental/controllers/reconcilers"
 	"github.com/grafana-operator/grafana-operator-experimental/controllers/reconcilers/grafana"
@@ -68,6 +70,7 @@ func (r *GrafanaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if err != nil {
 		if errors.IsNotFound(err) {
 			controllerLog.Info("grafana cr has been deleted", "name", req.NamespacedName)
+			grafanaclient.DeleteInventory(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 
@@ -86,6 +89,7 @@ func (r *GrafanaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		nextStatus.Stage = grafanav1beta1.OperatorStageComplete
 		nextStatus.StageStatus = grafanav1beta1.OperatorStageResultSuccess
 		nextStatus.AdminUrl = grafana.Spec.External.URL
+		nextStatus.SetSyncConditions(grafana.Generation, grafanav1beta1.ReasonSynced, "")
 		return r.updateStatus(grafana, nextStatus)
 	}
 
@@ -119,8 +123,14 @@ func (r *GrafanaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 	}
 
-	if finished {
+	switch {
+	case finished:
 		controllerLog.Info("grafana installation complete")
+		nextStatus.SetSyncConditions(grafana.Generation, grafanav1beta1.ReasonSynced, "")
+	case nextStatus.LastMessage != "":
+		nextStatus.SetSyncConditions(grafana.Generation, grafanav1beta1.ReasonSyncFailed, fmt.Sprintf("stage %v failed: %v", nextStatus.Stage, nextStatus.LastMessage))
+	default:
+		nextStatus.SetSyncConditions(grafana.Generation, grafanav1beta1.ReasonInProgress, fmt.Sprintf("waiting for stage %v", nextStatus.Stage))
 	}
 
 	return r.updateStatus(grafana, nextStatus)
@@ -156,6 +166,7 @@ func (r *GrafanaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&grafanav1beta1.Grafana{}).
 		Owns(&v1.Deployment{}).
 		Owns(&v12.ConfigMap{}).
+		Owns(&v12.Secret{}).
 		Complete(r)
 }
 
@@ -168,6 +179,7 @@ func getInstallationStages() []grafanav1beta1.OperatorStageName {
 		grafanav1beta1.OperatorStageService,
 		grafanav1beta1.OperatorStageIngress,
 		grafanav1beta1.OperatorStagePlugins,
+		grafanav1beta1.OperatorStageProvisioning,
 		grafanav1beta1.OperatorStageDeployment,
 		grafanav1beta1.OperatorStageComplete,
 	}
@@ -189,6 +201,8 @@ func (r *GrafanaReconciler) getReconcilerForStage(stage grafanav1beta1.OperatorS
 		return grafana.NewIngressReconciler(r.Client, r.IsOpenShift)
 	case grafanav1beta1.OperatorStagePlugins:
 		return grafana.NewPluginsReconciler(r.Client)
+	case grafanav1beta1.OperatorStageProvisioning:
+		return grafana.NewProvisioningReconciler(r.Client)
 	case grafanav1beta1.OperatorStageDeployment:
 		return grafana.NewDeploymentReconciler(r.Client, r.IsOpenShift)
 	case grafanav1beta1.OperatorStageComplete:
@@ -0,0 +1,255 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	client2 "github.com/grafana-operator/grafana-operator-experimental/controllers/client"
+	grapi "github.com/grafana/grafana-api-golang-client"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GrafanaDashboardExportReconciler reconciles a GrafanaDashboardExport object
+type GrafanaDashboardExportReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadashboardexports,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadashboardexports/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadashboardexports/finalizers,verbs=update
+
+// Reconcile exports the dashboards of the matching instances that are not managed by the operator to a config map
+// and requeues the export after the resync period
+func (r *GrafanaDashboardExportReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	controllerLog := log.FromContext(ctx)
+	r.Log = controllerLog
+
+	export := &v1beta1.GrafanaDashboardExport{}
+	err := r.Client.Get(ctx, client.ObjectKey{
+		Namespace: req.Namespace,
+		Name:      req.Name,
+	}, export)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// the config map is removed by the garbage collector
+			return ctrl.Result{}, nil
+		}
+		controllerLog.Error(err, "error getting grafana dashboard export cr")
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+
+	instances, err := GetMatchingInstances(ctx, r.Client, export.Spec.InstanceSelector, export.Spec.InstanceNamespaceSelector)
+	if err != nil {
+		controllerLog.Error(err, "could not find matching instances", "name", export.Name, "namespace", export.Namespace)
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+
+	state := newSyncState(nil)
+	if len(instances.Items) == 0 {
+		export.Status.NoMatchingInstances = true
+		reason, message := state.getReason()
+		export.Status.SetSyncConditions(export.Generation, reason, message)
+		return ctrl.Result{RequeueAfter: export.GetResyncPeriod()}, r.Client.Status().Update(ctx, export)
+	}
+
+	// export from the instances in a stable order, a dashboard found in several instances is exported once
+	sort.Slice(instances.Items, func(i, j int) bool {
+		return fmt.Sprintf("%v/%v", instances.Items[i].Namespace, instances.Items[i].Name) < fmt.Sprintf("%v/%v", instances.Items[j].Namespace, instances.Items[j].Name)
+	})
+
+	manifests := map[string]string{}
+	exportedUids := map[string]bool{}
+	names := map[string]bool{}
+
+	for _, grafana := range instances.Items {
+		// check if this is a cross namespace export
+		if grafana.Namespace != export.Namespace && !export.IsAllowCrossNamespaceImport() {
+			continue
+		}
+
+		grafana := grafana
+		if grafana.Status.Stage != v1beta1.OperatorStageComplete || grafana.Status.StageStatus != v1beta1.OperatorStageResultSuccess {
+			controllerLog.Info("grafana instance not ready", "grafana", grafana.Name)
+			state.addNotReady(&grafana)
+			continue
+		}
+
+		// a partial export would drop the dashboards of the failed instance from the config map
+		err = r.exportDashboards(ctx, &grafana, export, names, exportedUids, manifests)
+		if err != nil {
+			controllerLog.Error(err, "error exporting dashboards", "export", export.Name, "grafana", grafana.Name)
+			state.addError(&grafana, err)
+			reason, message := state.getReason()
+			export.Status.SetSyncConditions(export.Generation, reason, message)
+			if err := r.Client.Status().Update(ctx, export); err != nil {
+				controllerLog.Error(err, "error updating the status of the export", "export", export.Name)
+			}
+			return ctrl.Result{RequeueAfter: RequeueDelay}, err
+		}
+		state.addSynced(&grafana, "", "")
+	}
+
+	configMaps, err := r.writeExportConfigMaps(ctx, export, manifests)
+	if err != nil {
+		controllerLog.Error(err, "error writing exported dashboards", "export", export.Name)
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+
+	previous := export.Status.DeepCopy()
+	reason, message := state.getReason()
+	export.Status.SetSyncConditions(export.Generation, reason, message)
+	export.Status.NoMatchingInstances = false
+	export.Status.ExportedDashboards = len(manifests)
+	export.Status.ConfigMaps = configMaps
+	if hash := hashExportManifests(manifests); hash != export.Status.Hash {
+		export.Status.Hash = hash
+		export.Status.LastExportTime = metav1.Now()
+	}
+	if !reflect.DeepEqual(previous, &export.Status) {
+		err = r.Client.Status().Update(ctx, export)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: RequeueDelay}, err
+		}
+	}
+
+	// instances that are not ready yet are exported once they are
+	if !state.success() {
+		return ctrl.Result{RequeueAfter: RequeueDelay}, nil
+	}
+	return ctrl.Result{RequeueAfter: export.GetResyncPeriod()}, nil
+}
+
+// writeExportConfigMaps writes the manifests to the config maps of the export and deletes the config maps of previous
+// exports that are no longer needed, it returns the names of the config maps
+func (r *GrafanaDashboardExportReconciler) writeExportConfigMaps(ctx context.Context, export *v1beta1.GrafanaDashboardExport, manifests map[string]string) ([]string, error) {
+	var names []string
+	for i, data := range splitExportManifests(manifests) {
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      getExportConfigMapName(export, i),
+				Namespace: export.Namespace,
+			},
+		}
+
+		data := data
+		_, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+			configMap.Data = data
+			return controllerutil.SetControllerReference(export, configMap, r.Scheme)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to write config map %v: %v", configMap.Name, err)
+		}
+		names = append(names, configMap.Name)
+	}
+
+	written := map[string]bool{}
+	for _, name := range names {
+		written[name] = true
+	}
+	for _, name := range export.Status.ConfigMaps {
+		if written[name] {
+			continue
+		}
+		configMap := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: export.Namespace,
+			},
+		}
+		err := r.Client.Delete(ctx, configMap)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete config map %v: %v", name, err)
+		}
+	}
+
+	return names, nil
+}
+
+// exportDashboards adds the dashboards of an instance that are not managed by the operator to the manifests
+func (r *GrafanaDashboardExportReconciler) exportDashboards(ctx context.Context, grafana *v1beta1.Grafana, export *v1beta1.GrafanaDashboardExport, names map[string]bool, exportedUids map[string]bool, manifests map[string]string) error {
+	grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, grafana)
+	if err != nil {
+		return err
+	}
+
+	dashboards, err := client2.GetInventory(grafana).Dashboards(grafanaClient)
+	if err != nil {
+		return err
+	}
+
+	datasources, err := grafanaClient.DataSources()
+	if err != nil {
+		return err
+	}
+
+	// the inventory is shared, sort a copy
+	dashboards = append([]grapi.FolderDashboardSearchResponse{}, dashboards...)
+	sort.Slice(dashboards, func(i, j int) bool {
+		return dashboards[i].UID < dashboards[j].UID
+	})
+
+	exporter := &dashboardExporter{
+		export:      export,
+		grafana:     grafana,
+		datasources: datasources,
+		names:       names,
+	}
+
+	for _, dashboard := range dashboards {
+		if grafana.Status.Dashboards.ContainsUid(dashboard.UID) || exportedUids[dashboard.UID] {
+			continue
+		}
+
+		dashboardFromClient, err := grafanaClient.DashboardByUID(dashboard.UID)
+		if err != nil {
+			return err
+		}
+
+		name, manifest, err := exporter.exportDashboard(dashboard, dashboardFromClient.Model)
+		if err != nil {
+			return err
+		}
+
+		manifests[fmt.Sprintf("%v.yaml", name)] = string(manifest)
+		exportedUids[dashboard.UID] = true
+	}
+
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GrafanaDashboardExportReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.GrafanaDashboardExport{}).
+		Owns(&v1.ConfigMap{}).
+		Complete(r)
+}
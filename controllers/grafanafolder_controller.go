@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
@@ -116,6 +117,7 @@ func (r *GrafanaFolderReconciler) syncFolders(ctx context.Context) (ctrl.Result,
 			grafana.Status.Folders = grafana.Status.Folders.Remove(namespace, name)
 			foldersSynced += 1
 		}
+		client2.GetInventory(grafana).Invalidate()
 
 		// one update per grafana - this will trigger a reconcile of the grafana controller
 		// so we should minimize those updates
@@ -161,7 +163,7 @@ func (r *GrafanaFolderReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}, folder)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			if err := r.onFolderDeleted(ctx, req.Namespace, req.Name); err != nil {
+			if err := r.onFolderDeleted(ctx, req.Namespace, req.Name, nil); err != nil {
 				return ctrl.Result{RequeueAfter: RequeueDelay}, err
 			}
 			return ctrl.Result{}, nil
@@ -170,6 +172,17 @@ func (r *GrafanaFolderReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{RequeueAfter: RequeueDelay}, err
 	}
 
+	deleting, err := reconcileFinalizer(ctx, r.Client, folder, func() error {
+		return r.finalizeFolder(ctx, folder)
+	})
+	if err != nil {
+		controllerLog.Error(err, "error finalizing grafana folder cr", "name", folder.Name, "namespace", folder.Namespace)
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+	if deleting {
+		return ctrl.Result{}, nil
+	}
+
 	instances, err := r.GetMatchingFolderInstances(ctx, folder, r.Client)
 	if err != nil {
 		controllerLog.Error(err, "could not find matching instances", "name", folder.Name, "namespace", folder.Namespace)
@@ -178,6 +191,8 @@ func (r *GrafanaFolderReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	controllerLog.Info("found matching Grafana instances for folder", "count", len(instances.Items))
 
+	previous := folder.Status.DeepCopy()
+	state := newSyncState(folder.Status.Instances)
 	for _, grafana := range instances.Items {
 		// check if this is a cross namespace import
 		if grafana.Namespace != folder.Namespace && !folder.IsAllowCrossNamespaceImport() {
@@ -187,16 +202,42 @@ func (r *GrafanaFolderReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		grafana := grafana
 		if grafana.Status.Stage != v1beta1.OperatorStageComplete || grafana.Status.StageStatus != v1beta1.OperatorStageResultSuccess {
 			controllerLog.Info("grafana instance not ready", "grafana", grafana.Name)
+			state.addNotReady(&grafana)
+			continue
+		}
+
+		// folders are created through the api, they wouldn't survive a restart of the instance
+		if grafana.IsFileProvisioning() {
+			state.addError(&grafana, unsupportedError{fmt.Errorf("folders are not supported by instances with file provisioning")})
 			continue
 		}
 
 		err = r.onFolderCreated(ctx, &grafana, folder)
 		if err != nil {
 			controllerLog.Error(err, "error reconciling folder", "folder", folder.Name, "grafana", grafana.Name)
+			state.addError(&grafana, err)
+		}
+		state.addSynced(&grafana, grafana.Status.Folders.GetUid(folder.Namespace, folder.Name), folder.Hash())
+		state.setPermissions(&grafana, len(folder.Spec.Permissions) > 0 && folder.Spec.Json != "")
+	}
+	folder.Status.Instances = state.getInstances()
+
+	reason, message := state.getReason()
+	folder.Status.SetSyncConditions(folder.Generation, reason, message, state.stalled())
+	if !reflect.DeepEqual(previous, &folder.Status) {
+		err = r.Client.Status().Update(ctx, folder)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: RequeueDelay}, err
 		}
 	}
 
-	return ctrl.Result{}, nil
+	// if the folder was successfully synced in all instances, wait for its re-sync period to apply the permissions
+	// again and to recreate the folder if it was removed from an instance
+	if state.success() {
+		return ctrl.Result{RequeueAfter: folder.GetResyncPeriod()}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: RequeueDelay}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -236,39 +277,44 @@ func (r *GrafanaFolderReconciler) SetupWithManager(mgr ctrl.Manager, ctx context
 	return err
 }
 
-func (r *GrafanaFolderReconciler) onFolderDeleted(ctx context.Context, namespace string, name string) error {
-	list := v1beta1.GrafanaList{}
-	var opts []client.ListOption
-	err := r.Client.List(ctx, &list, opts...)
+// onFolderDeleted removes the folder from all instances it was synced to
+func (r *GrafanaFolderReconciler) onFolderDeleted(ctx context.Context, namespace string, name string, synced v1beta1.InstanceSyncStatusList) error {
+	instances, err := getSyncedInstances(ctx, r.Client, namespace, name, synced, folderStatusList)
 	if err != nil {
 		return err
 	}
 
-	for _, grafana := range list.Items {
-		grafana := grafana
-		if found, uid := grafana.Status.Folders.Find(namespace, name); found {
-			grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, &grafana)
-			if err != nil {
-				return err
-			}
-
-			err = grafanaClient.DeleteFolder(*uid)
-			if err != nil {
-				if !strings.Contains(err.Error(), "status: 404") {
-					return err
-				}
-			}
+	for _, instance := range instances {
+		grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, instance.grafana)
+		if err != nil {
+			return err
+		}
 
-			grafana.Status.Folders = grafana.Status.Folders.Remove(namespace, name)
-			err = r.Client.Status().Update(ctx, &grafana)
-			if err != nil {
+		err = grafanaClient.DeleteFolder(instance.uid)
+		if err != nil {
+			if !strings.Contains(err.Error(), "status: 404") {
 				return err
 			}
 		}
+		client2.GetInventory(instance.grafana).Invalidate()
+
+		instance.grafana.Status.Folders = instance.grafana.Status.Folders.Remove(namespace, name)
+		err = r.Client.Status().Update(ctx, instance.grafana)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// finalizeFolder removes the folder from the instances before the CR is deleted, unless the deletion policy retains it
+func (r *GrafanaFolderReconciler) finalizeFolder(ctx context.Context, cr *v1beta1.GrafanaFolder) error {
+	if cr.GetDeletionPolicy() == v1beta1.DeletionPolicyRetain {
+		return releaseContent(ctx, r.Client, cr.Namespace, cr.Name, folderStatusList)
+	}
+	return r.onFolderDeleted(ctx, cr.Namespace, cr.Name, cr.Status.Instances)
+}
+
 func (r *GrafanaFolderReconciler) onFolderCreated(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaFolder) error {
 	if cr.Spec.Json == "" {
 		return nil
@@ -283,8 +329,8 @@ func (r *GrafanaFolderReconciler) onFolderCreated(ctx context.Context, grafana *
 	if err != nil {
 		return err
 	}
-	if exists && cr.Unchanged() {
-		return nil
+	if exists && cr.Unchanged(grafana) {
+		return ReconcileFolderPermissions(grafanaClient, string(cr.UID), cr.Spec.Permissions, cr.Status.Instances.HasPermissions(grafana))
 	}
 
 	var folderFromJson map[string]interface{}
@@ -299,13 +345,15 @@ func (r *GrafanaFolderReconciler) onFolderCreated(ctx context.Context, grafana *
 	}
 
 	// folder exists, update only
-	if exists && !cr.Unchanged() {
+	if exists && !cr.Unchanged(grafana) {
 		err = grafanaClient.UpdateFolder(string(cr.UID), title)
 		if err != nil {
 			return err
 		}
+		client2.GetInventory(grafana).Invalidate()
+		cr.Status.Hash = cr.Hash()
 
-		return r.UpdateStatus(ctx, cr)
+		return ReconcileFolderPermissions(grafanaClient, string(cr.UID), cr.Spec.Permissions, cr.Status.Instances.HasPermissions(grafana))
 	}
 
 	folderFromClient, err := grafanaClient.NewFolder(title, string(cr.UID))
@@ -316,6 +364,7 @@ func (r *GrafanaFolderReconciler) onFolderCreated(ctx context.Context, grafana *
 		}
 		return err
 	}
+	client2.GetInventory(grafana).Invalidate()
 
 	// FIXME our current version of the client doesn't return response codes, or any response for
 	// FIXME that matter, this needs an issue/feature request upstream
@@ -330,29 +379,24 @@ func (r *GrafanaFolderReconciler) onFolderCreated(ctx context.Context, grafana *
 		return err
 	}
 
-	return r.UpdateStatus(ctx, cr)
-}
-
-func (r *GrafanaFolderReconciler) UpdateStatus(ctx context.Context, cr *v1beta1.GrafanaFolder) error {
 	cr.Status.Hash = cr.Hash()
-	return r.Client.Status().Update(ctx, cr)
+
+	return ReconcileFolderPermissions(grafanaClient, folderFromClient.UID, cr.Spec.Permissions, cr.Status.Instances.HasPermissions(grafana))
 }
 
 func (r *GrafanaFolderReconciler) Exists(client *grapi.Client, cr *v1beta1.GrafanaFolder) (bool, error) {
-	folders, err := client.Folders()
+	_, err := client.FolderByUID(string(cr.UID))
 	if err != nil {
-		return false, err
-	}
-	for _, folder := range folders {
-		if folder.UID == string(cr.UID) {
-			return true, nil
+		if strings.Contains(err.Error(), "status: 404") {
+			return false, nil
 		}
+		return false, err
 	}
-	return false, nil
+	return true, nil
 }
 
 func (r *GrafanaFolderReconciler) GetMatchingFolderInstances(ctx context.Context, folder *v1beta1.GrafanaFolder, k8sClient client.Client) (v1beta1.GrafanaList, error) {
-	instances, err := GetMatchingInstances(ctx, k8sClient, folder.Spec.InstanceSelector)
+	instances, err := GetMatchingInstances(ctx, k8sClient, folder.Spec.InstanceSelector, folder.Spec.InstanceNamespaceSelector)
 	if err != nil || len(instances.Items) == 0 {
 		folder.Status.NoMatchingInstances = true
 		if err := r.Client.Status().Update(ctx, folder); err != nil {
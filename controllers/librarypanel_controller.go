@@ -0,0 +1,454 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	client2 "github.com/grafana-operator/grafana-operator-experimental/controllers/client"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/metrics"
+	grapi "github.com/grafana/grafana-api-golang-client"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GrafanaLibraryPanelReconciler reconciles a GrafanaLibraryPanel object
+type GrafanaLibraryPanelReconciler struct {
+	Client client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanalibrarypanels,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanalibrarypanels/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanalibrarypanels/finalizers,verbs=update
+
+func (r *GrafanaLibraryPanelReconciler) syncLibraryPanels(ctx context.Context) (ctrl.Result, error) {
+	syncLog := log.FromContext(ctx)
+	panelsSynced := 0
+
+	// get all grafana instances
+	grafanas := &v1beta1.GrafanaList{}
+	var opts []client.ListOption
+	err := r.Client.List(ctx, grafanas, opts...)
+	if err != nil {
+		return ctrl.Result{
+			Requeue: true,
+		}, err
+	}
+
+	// no instances, no need to sync
+	if len(grafanas.Items) == 0 {
+		return ctrl.Result{Requeue: false}, nil
+	}
+
+	// get all library panels
+	allPanels := &v1beta1.GrafanaLibraryPanelList{}
+	err = r.Client.List(ctx, allPanels, opts...)
+	if err != nil {
+		return ctrl.Result{
+			Requeue: true,
+		}, err
+	}
+
+	panelsToDelete := getLibraryPanelsToDelete(allPanels, grafanas.Items)
+
+	// delete all library panels that no longer have a cr
+	for grafana, panels := range panelsToDelete {
+		grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, grafana)
+		if err != nil {
+			return ctrl.Result{Requeue: true}, err
+		}
+
+		for _, panel := range panels {
+			// avoid bombarding the grafana instance with a large number of requests at once, limit
+			// the sync to a certain number of library panels per cycle.
+			if panelsSynced >= syncBatchSize {
+				return ctrl.Result{Requeue: true}, nil
+			}
+
+			namespace, name, uid := panel.Split()
+			_, err = grafanaClient.DeleteLibraryPanel(uid)
+			if err != nil {
+				if strings.Contains(err.Error(), "status: 404") {
+					syncLog.Info("library panel no longer exists", "namespace", namespace, "name", name)
+				} else {
+					return ctrl.Result{Requeue: false}, err
+				}
+			}
+
+			grafana.Status.LibraryPanels = grafana.Status.LibraryPanels.Remove(namespace, name)
+			panelsSynced += 1
+		}
+
+		// one update per grafana - this will trigger a reconcile of the grafana controller
+		// so we should minimize those updates
+		err = r.Client.Status().Update(ctx, grafana)
+		if err != nil {
+			return ctrl.Result{Requeue: false}, err
+		}
+	}
+
+	if panelsSynced > 0 {
+		syncLog.Info("successfully synced library panels", "panels", panelsSynced)
+	}
+	return ctrl.Result{Requeue: false}, nil
+}
+
+// getLibraryPanelsToDelete returns the library panels in the instances that no longer have a cr
+func getLibraryPanelsToDelete(allPanels *v1beta1.GrafanaLibraryPanelList, grafanas []v1beta1.Grafana) map[*v1beta1.Grafana][]v1beta1.NamespacedResource {
+	panelsToDelete := map[*v1beta1.Grafana][]v1beta1.NamespacedResource{}
+	for _, grafana := range grafanas {
+		grafana := grafana
+		for _, panel := range grafana.Status.LibraryPanels {
+			if allPanels.Find(panel.Namespace(), panel.Name()) == nil {
+				panelsToDelete[&grafana] = append(panelsToDelete[&grafana], panel)
+			}
+		}
+	}
+	return panelsToDelete
+}
+
+func (r *GrafanaLibraryPanelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	controllerLog := log.FromContext(ctx)
+	r.Log = controllerLog
+
+	// periodic sync reconcile
+	if req.Namespace == "" && req.Name == "" {
+		start := time.Now()
+		syncResult, err := r.syncLibraryPanels(ctx)
+		elapsed := time.Since(start).Milliseconds()
+		metrics.InitialLibraryPanelsSyncDuration.Set(float64(elapsed))
+		return syncResult, err
+	}
+
+	panel := &v1beta1.GrafanaLibraryPanel{}
+	err := r.Client.Get(ctx, client.ObjectKey{
+		Namespace: req.Namespace,
+		Name:      req.Name,
+	}, panel)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			err = r.onLibraryPanelDeleted(ctx, req.Namespace, req.Name, nil)
+			if err != nil {
+				return ctrl.Result{RequeueAfter: RequeueDelay}, err
+			}
+			return ctrl.Result{}, nil
+		}
+		controllerLog.Error(err, "error getting grafana library panel cr")
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+
+	deleting, err := reconcileFinalizer(ctx, r.Client, panel, func() error {
+		return r.finalizeLibraryPanel(ctx, panel)
+	})
+	if err != nil {
+		controllerLog.Error(err, "error finalizing grafana library panel cr", "name", panel.Name, "namespace", panel.Namespace)
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+	if deleting {
+		return ctrl.Result{}, nil
+	}
+
+	instances, err := r.GetMatchingLibraryPanelInstances(ctx, panel)
+	if err != nil {
+		controllerLog.Error(err, "could not find matching instances", "name", panel.Name, "namespace", panel.Namespace)
+		return ctrl.Result{RequeueAfter: RequeueDelay}, err
+	}
+
+	controllerLog.Info("found matching Grafana instances for library panel", "count", len(instances.Items))
+
+	previous := panel.Status.DeepCopy()
+	state := newSyncState(panel.Status.Instances)
+	for _, grafana := range instances.Items {
+		// check if this is a cross namespace import
+		if grafana.Namespace != panel.Namespace && !panel.IsAllowCrossNamespaceImport() {
+			continue
+		}
+
+		grafana := grafana
+		if grafana.Status.Stage != v1beta1.OperatorStageComplete || grafana.Status.StageStatus != v1beta1.OperatorStageResultSuccess {
+			controllerLog.Info("grafana instance not ready", "grafana", grafana.Name)
+			state.addNotReady(&grafana)
+			continue
+		}
+
+		// library panels are created through the api, they wouldn't survive a restart of the instance
+		if grafana.IsFileProvisioning() {
+			state.addError(&grafana, unsupportedError{fmt.Errorf("library panels are not supported by instances with file provisioning")})
+			continue
+		}
+
+		hash, err := r.onLibraryPanelCreated(ctx, &grafana, panel)
+		if err != nil {
+			controllerLog.Error(err, "error reconciling library panel", "panel", panel.Name, "grafana", grafana.Name)
+			state.addError(&grafana, err)
+		}
+		state.addSynced(&grafana, grafana.Status.LibraryPanels.GetUid(panel.Namespace, panel.Name), hash)
+	}
+	panel.Status.Instances = state.getInstances()
+
+	reason, message := state.getReason()
+	panel.Status.SetSyncConditions(panel.Generation, reason, message, state.stalled())
+	if !reflect.DeepEqual(previous, &panel.Status) {
+		err = r.Client.Status().Update(ctx, panel)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: RequeueDelay}, err
+		}
+	}
+
+	// if the library panel was successfully synced in all instances, wait for its re-sync period
+	if state.success() {
+		return ctrl.Result{RequeueAfter: panel.GetResyncPeriod()}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: RequeueDelay}, nil
+}
+
+// onLibraryPanelDeleted removes the library panel from all instances it was synced to
+func (r *GrafanaLibraryPanelReconciler) onLibraryPanelDeleted(ctx context.Context, namespace string, name string, synced v1beta1.InstanceSyncStatusList) error {
+	instances, err := getSyncedInstances(ctx, r.Client, namespace, name, synced, libraryPanelStatusList)
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, instance.grafana)
+		if err != nil {
+			return err
+		}
+
+		_, err = grafanaClient.DeleteLibraryPanel(instance.uid)
+		if err != nil {
+			if !strings.Contains(err.Error(), "status: 404") {
+				return err
+			}
+		}
+
+		instance.grafana.Status.LibraryPanels = instance.grafana.Status.LibraryPanels.Remove(namespace, name)
+		err = r.Client.Status().Update(ctx, instance.grafana)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// finalizeLibraryPanel removes the library panel from the instances before the CR is deleted, unless the deletion
+// policy retains it
+func (r *GrafanaLibraryPanelReconciler) finalizeLibraryPanel(ctx context.Context, cr *v1beta1.GrafanaLibraryPanel) error {
+	if cr.GetDeletionPolicy() == v1beta1.DeletionPolicyRetain {
+		return releaseContent(ctx, r.Client, cr.Namespace, cr.Name, libraryPanelStatusList)
+	}
+	return r.onLibraryPanelDeleted(ctx, cr.Namespace, cr.Name, cr.Status.Instances)
+}
+
+func (r *GrafanaLibraryPanelReconciler) onLibraryPanelCreated(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaLibraryPanel) (string, error) {
+	panelJson, err := r.fetchLibraryPanelJson(ctx, grafana, cr)
+	if err != nil {
+		return "", err
+	}
+
+	// panels come from different sources, the hash of the fetched json is used to notice changes in any of them
+	hash := cr.HashContent(panelJson)
+
+	var model map[string]interface{}
+	err = json.Unmarshal(panelJson, &model)
+	if err != nil {
+		return "", err
+	}
+
+	jsonUid, _ := model["uid"].(string)
+	uid := cr.GetUid(jsonUid)
+
+	for _, panel := range grafana.Status.LibraryPanels {
+		namespace, name, panelUid := panel.Split()
+		if panelUid == uid && (namespace != cr.Namespace || name != cr.Name) {
+			return "", fmt.Errorf("uid %v is already used by library panel %v/%v in instance %v/%v", uid, namespace, name, grafana.Namespace, grafana.Name)
+		}
+	}
+
+	grafanaClient, err := client2.NewGrafanaClient(ctx, r.Client, grafana)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := grafanaClient.LibraryPanelByUID(uid)
+	if err != nil {
+		if !strings.Contains(err.Error(), "status: 404") {
+			return "", err
+		}
+		existing = nil
+	}
+
+	// the uid of the library panel changed since it was imported into the instance
+	_, previousUid := grafana.Status.LibraryPanels.Find(cr.Namespace, cr.Name)
+	uidChanged := previousUid != nil && *previousUid != uid
+
+	if existing != nil && cr.Unchanged(grafana, hash) && !uidChanged {
+		return hash, nil
+	}
+
+	folderID, err := r.GetFolderID(grafanaClient, grafana, cr)
+	if err != nil {
+		return "", err
+	}
+
+	name := cr.Name
+	if title, ok := model["title"].(string); ok && title != "" {
+		name = title
+	}
+
+	model["uid"] = uid
+	panel := grapi.LibraryPanel{
+		Folder: folderID,
+		Name:   name,
+		Model:  model,
+		UID:    uid,
+	}
+
+	if existing != nil {
+		panel.Version = existing.Version
+		_, err = grafanaClient.PatchLibraryPanel(uid, panel)
+	} else {
+		_, err = grafanaClient.NewLibraryPanel(panel)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// library panels that are still used by dashboards can't be deleted, they are left in the instance
+	if uidChanged {
+		_, err = grafanaClient.DeleteLibraryPanel(*previousUid)
+		if err != nil && !strings.Contains(err.Error(), "status: 404") {
+			r.Log.Error(err, "failed to delete library panel under its previous uid", "panel", cr.Name, "grafana", grafana.Name, "uid", *previousUid)
+		}
+	}
+
+	grafana.Status.LibraryPanels = grafana.Status.LibraryPanels.Remove(cr.Namespace, cr.Name).Add(cr.Namespace, cr.Name, uid)
+	err = r.Client.Status().Update(ctx, grafana)
+	if err != nil {
+		return "", err
+	}
+
+	cr.Status.Hash = hash
+	return hash, nil
+}
+
+// fetchLibraryPanelJson obtains the panel json with the dashboard fetchers, library panels support a subset of the
+// dashboard sources
+func (r *GrafanaLibraryPanelReconciler) fetchLibraryPanelJson(ctx context.Context, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaLibraryPanel) ([]byte, error) {
+	source := cr.GetSourceDashboard()
+
+	dashboards := &GrafanaDashboardReconciler{
+		Client: r.Client,
+		Log:    r.Log,
+		Scheme: r.Scheme,
+	}
+
+	panelJson, err := dashboards.fetchCachedDashboardJson(ctx, grafana, source, cr, v1beta1.GroupVersion.WithKind("GrafanaLibraryPanel"))
+	if err != nil {
+		return nil, err
+	}
+
+	cr.SetContentCache(source)
+	return panelJson, nil
+}
+
+// GetFolderID returns the id of the folder created for the referenced GrafanaFolder, or 0 for the General folder
+func (r *GrafanaLibraryPanelReconciler) GetFolderID(client *grapi.Client, grafana *v1beta1.Grafana, cr *v1beta1.GrafanaLibraryPanel) (int64, error) {
+	if cr.Spec.FolderRef == nil {
+		return 0, nil
+	}
+
+	namespace := cr.GetFolderRefNamespace()
+	found, uid := grafana.Status.Folders.Find(namespace, cr.Spec.FolderRef.Name)
+	if !found {
+		return 0, fmt.Errorf("folder %v/%v referenced by library panel %v/%v is not ready in instance %v/%v", namespace, cr.Spec.FolderRef.Name, cr.Namespace, cr.Name, grafana.Namespace, grafana.Name)
+	}
+
+	folder, err := client.FolderByUID(*uid)
+	if err != nil {
+		return 0, err
+	}
+	return folder.ID, nil
+}
+
+func (r *GrafanaLibraryPanelReconciler) GetMatchingLibraryPanelInstances(ctx context.Context, panel *v1beta1.GrafanaLibraryPanel) (v1beta1.GrafanaList, error) {
+	instances, err := GetMatchingInstances(ctx, r.Client, panel.Spec.InstanceSelector, panel.Spec.InstanceNamespaceSelector)
+	if err != nil || len(instances.Items) == 0 {
+		panel.Status.NoMatchingInstances = true
+		if err := r.Client.Status().Update(ctx, panel); err != nil {
+			r.Log.Error(err, "unable to update the status of library panel", "name", panel.Name, "namespace", panel.Namespace)
+		}
+		return v1beta1.GrafanaList{}, err
+	}
+	panel.Status.NoMatchingInstances = false
+	if err := r.Client.Status().Update(ctx, panel); err != nil {
+		r.Log.Error(err, "unable to update the status of library panel", "name", panel.Name, "namespace", panel.Namespace)
+	}
+
+	return instances, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GrafanaLibraryPanelReconciler) SetupWithManager(mgr ctrl.Manager, ctx context.Context) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.GrafanaLibraryPanel{}).
+		Complete(r)
+
+	if err == nil {
+		d, err := time.ParseDuration(initialSyncDelay)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(d):
+					result, err := r.Reconcile(ctx, ctrl.Request{})
+					if err != nil {
+						r.Log.Error(err, "error synchronizing library panels")
+						continue
+					}
+					if result.Requeue {
+						r.Log.Info("more library panels left to synchronize")
+						continue
+					}
+					r.Log.Info("library panel sync complete")
+					return
+				}
+			}
+		}()
+	}
+
+	return err
+}
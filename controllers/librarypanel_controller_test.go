@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetLibraryPanelsToDelete(t *testing.T) {
+	panels := &v1beta1.GrafanaLibraryPanelList{
+		Items: []v1beta1.GrafanaLibraryPanel{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "requests",
+					Namespace: "grafana-operator-system",
+				},
+			},
+		},
+	}
+	grafanas := []v1beta1.Grafana{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "grafana",
+				Namespace: "grafana-operator-system",
+			},
+			Status: v1beta1.GrafanaStatus{
+				LibraryPanels: v1beta1.NamespacedResourceList{
+					"grafana-operator-system/requests/requests",
+					"grafana-operator-system/errors/errors",
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "empty",
+				Namespace: "grafana-operator-system",
+			},
+		},
+	}
+
+	panelsToDelete := getLibraryPanelsToDelete(panels, grafanas)
+	assert.Len(t, panelsToDelete, 1)
+	for grafana, toDelete := range panelsToDelete {
+		assert.Equal(t, "grafana", grafana.Name)
+		assert.Equal(t, []v1beta1.NamespacedResource{"grafana-operator-system/errors/errors"}, toDelete)
+	}
+}
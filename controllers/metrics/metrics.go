@@ -54,6 +54,27 @@ var (
 		Name:      "initial_sync_duration",
 		Help:      "time in ms to sync folders after operator restart",
 	})
+
+	InitialLibraryPanelsSyncDuration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "library_panels",
+		Name:      "initial_sync_duration",
+		Help:      "time in ms to sync library panels after operator restart",
+	})
+
+	DatasourceHealth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "datasources",
+		Name:      "health",
+		Help:      "result of the last health check per datasource and instance, 1 if the datasource is healthy",
+	}, []string{"namespace", "datasource", "instance"})
+
+	DatasourceHealthCheckDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana_operator",
+		Subsystem: "datasources",
+		Name:      "health_check_duration",
+		Help:      "time in ms of the last health check per datasource and instance",
+	}, []string{"namespace", "datasource", "instance"})
 )
 
 func init() {
@@ -64,4 +85,7 @@ func init() {
 	metrics.Registry.MustRegister(InitialDashboardSyncDuration)
 	metrics.Registry.MustRegister(InitialDatasourceSyncDuration)
 	metrics.Registry.MustRegister(InitialFoldersSyncDuration)
+	metrics.Registry.MustRegister(InitialLibraryPanelsSyncDuration)
+	metrics.Registry.MustRegister(DatasourceHealth)
+	metrics.Registry.MustRegister(DatasourceHealthCheckDuration)
 }
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"fmt"
+
+	grafanav1beta1 "github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func GetDashboardProvidersConfigMap(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v1.ConfigMap {
+	config := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-dashboard-providers", cr.Name),
+			Namespace: cr.Namespace,
+		},
+	}
+	controllerutil.SetOwnerReference(cr, config, scheme) //nolint:errcheck
+	return config
+}
+
+// ProvisionedDashboardsConfigMaps is the number of config maps the provisioned dashboards of an instance are spread
+// across, they are all mounted into the same directory
+const ProvisionedDashboardsConfigMaps = 10
+
+// ProvisionedDashboardsConfigMapSize is the maximum size in bytes of the dashboards written to a single config map,
+// the size of a config map is limited to 1MiB
+var ProvisionedDashboardsConfigMapSize = 768 * 1024
+
+// GetProvisionedDashboardsConfigMaps returns the config maps dashboards are provisioned from. The first one keeps the
+// name of the single config map used before, the others get a numeric suffix.
+func GetProvisionedDashboardsConfigMaps(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) []*v1.ConfigMap {
+	var configMaps []*v1.ConfigMap
+	for i := 0; i < ProvisionedDashboardsConfigMaps; i++ {
+		name := fmt.Sprintf("%s-dashboards", cr.Name)
+		if i > 0 {
+			name = fmt.Sprintf("%s-dashboards-%d", cr.Name, i)
+		}
+
+		config := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: cr.Namespace,
+			},
+		}
+		controllerutil.SetOwnerReference(cr, config, scheme) //nolint:errcheck
+		configMaps = append(configMaps, config)
+	}
+	return configMaps
+}
+
+// GetProvisionedDatasourcesSecret returns the secret datasources are provisioned from. Grafana only reads datasources
+// at startup, the instance is the controller of the secret so that changes restart it.
+func GetProvisionedDatasourcesSecret(cr *grafanav1beta1.Grafana, scheme *runtime.Scheme) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-datasources", cr.Name),
+			Namespace: cr.Namespace,
+		},
+	}
+	controllerutil.SetControllerReference(cr, secret, scheme) //nolint:errcheck
+	return secret
+}
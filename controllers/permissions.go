@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	grapi "github.com/grafana/grafana-api-golang-client"
+)
+
+// ReconcileDashboardPermissions replaces the permissions of a dashboard if they differ from the desired ones. Nothing
+// is done when no permissions are set, so that dashboards keep the default permissions of their folder. Permissions
+// that were applied before are removed once they are no longer set.
+func ReconcileDashboardPermissions(client *grapi.Client, id int64, permissions []v1beta1.Permission, applied bool) error {
+	if len(permissions) == 0 && !applied {
+		return nil
+	}
+
+	desired, err := resolvePermissionItems(client, permissions)
+	if err != nil {
+		return err
+	}
+
+	current, err := client.DashboardPermissions(id)
+	if err != nil {
+		return err
+	}
+
+	var actual []*grapi.PermissionItem
+	for _, permission := range current {
+		// inherited permissions are managed by the folder
+		if permission.Inherited {
+			continue
+		}
+		actual = append(actual, &grapi.PermissionItem{
+			Role:       permission.Role,
+			TeamID:     permission.TeamID,
+			UserID:     permission.UserID,
+			Permission: permission.Permission,
+		})
+	}
+
+	if permissionItemsEqual(actual, desired) {
+		return nil
+	}
+
+	return client.UpdateDashboardPermissions(id, &grapi.PermissionItems{Items: desired})
+}
+
+// ReconcileFolderPermissions replaces the permissions of a folder if they differ from the desired ones. Nothing is
+// done when no permissions are set. Permissions that were applied before are reset to the defaults of Grafana once
+// they are no longer set.
+func ReconcileFolderPermissions(client *grapi.Client, uid string, permissions []v1beta1.Permission, applied bool) error {
+	if len(permissions) == 0 && !applied {
+		return nil
+	}
+
+	desired, err := resolvePermissionItems(client, permissions)
+	if err != nil {
+		return err
+	}
+	if len(permissions) == 0 {
+		desired = defaultFolderPermissionItems()
+	}
+
+	current, err := client.FolderPermissions(uid)
+	if err != nil {
+		return err
+	}
+
+	actual := make([]*grapi.PermissionItem, 0, len(current))
+	for _, permission := range current {
+		actual = append(actual, &grapi.PermissionItem{
+			Role:       permission.Role,
+			TeamID:     permission.TeamID,
+			UserID:     permission.UserID,
+			Permission: permission.Permission,
+		})
+	}
+
+	if permissionItemsEqual(actual, desired) {
+		return nil
+	}
+
+	return client.UpdateFolderPermissions(uid, &grapi.PermissionItems{Items: desired})
+}
+
+// defaultFolderPermissionItems returns the permissions Grafana gives new folders
+func defaultFolderPermissionItems() []*grapi.PermissionItem {
+	return []*grapi.PermissionItem{
+		{Role: "Viewer", Permission: v1beta1.Permission{Permission: v1beta1.PermissionLevelView}.GetLevel()},
+		{Role: "Editor", Permission: v1beta1.Permission{Permission: v1beta1.PermissionLevelEdit}.GetLevel()},
+	}
+}
+
+// resolvePermissionItems looks up the ids of the teams and users referenced by name in the permissions
+func resolvePermissionItems(client *grapi.Client, permissions []v1beta1.Permission) ([]*grapi.PermissionItem, error) {
+	items := make([]*grapi.PermissionItem, 0, len(permissions))
+	for _, permission := range permissions {
+		item := &grapi.PermissionItem{
+			Permission: permission.GetLevel(),
+		}
+
+		switch {
+		case permission.Role != "" && permission.Team == "" && permission.User == "":
+			item.Role = permission.Role
+		case permission.Team != "" && permission.Role == "" && permission.User == "":
+			teams, err := client.SearchTeam(permission.Team)
+			if err != nil {
+				return nil, err
+			}
+			for _, team := range teams.Teams {
+				if team.Name == permission.Team {
+					item.TeamID = team.ID
+				}
+			}
+			if item.TeamID == 0 {
+				return nil, fmt.Errorf("team %v not found", permission.Team)
+			}
+		case permission.User != "" && permission.Role == "" && permission.Team == "":
+			user, err := client.UserByEmail(permission.User)
+			if err != nil {
+				return nil, fmt.Errorf("user %v not found: %v", permission.User, err)
+			}
+			item.UserID = user.ID
+		default:
+			return nil, fmt.Errorf("exactly one of role, team or user must be set in a permission")
+		}
+
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func permissionItemsEqual(a, b []*grapi.PermissionItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	key := func(item *grapi.PermissionItem) string {
+		return fmt.Sprintf("%v/%v/%v/%v", item.Role, item.TeamID, item.UserID, item.Permission)
+	}
+
+	keysA := make([]string, 0, len(a))
+	keysB := make([]string, 0, len(b))
+	for i := range a {
+		keysA = append(keysA, key(a[i]))
+		keysB = append(keysB, key(b[i]))
+	}
+	sort.Strings(keysA)
+	sort.Strings(keysB)
+
+	for i := range keysA {
+		if keysA[i] != keysB[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	grapi "github.com/grafana/grafana-api-golang-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileDashboardPermissions(t *testing.T) {
+	current := []map[string]interface{}{
+		{"role": "Viewer", "permission": 1},
+		{"role": "Editor", "permission": 2},
+		{"teamId": 3, "permission": 4, "inherited": true},
+	}
+	var updates []grapi.PermissionItems
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		switch {
+		case r.URL.Path == "/api/teams/search":
+			response = map[string]interface{}{"teams": []map[string]interface{}{{"id": 7, "name": "platform"}}}
+		case r.URL.Path == "/api/users/lookup":
+			response = map[string]interface{}{"id": 9, "login": r.URL.Query().Get("loginOrEmail")}
+		case r.URL.Path == "/api/dashboards/id/1/permissions" && r.Method == http.MethodGet:
+			response = current
+		case r.URL.Path == "/api/dashboards/id/1/permissions" && r.Method == http.MethodPost:
+			var items grapi.PermissionItems
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&items))
+			updates = append(updates, items)
+			response = map[string]interface{}{}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	defer ts.Close()
+
+	client, err := grapi.New(ts.URL, grapi.Config{})
+	assert.NoError(t, err)
+
+	// permissions that are not set are not managed
+	assert.NoError(t, ReconcileDashboardPermissions(client, 1, nil, false))
+	assert.Empty(t, updates)
+
+	// matching permissions are not updated, inherited permissions are ignored
+	assert.NoError(t, ReconcileDashboardPermissions(client, 1, []v1beta1.Permission{
+		{Role: "Editor", Permission: v1beta1.PermissionLevelEdit},
+		{Role: "Viewer", Permission: v1beta1.PermissionLevelView},
+	}, false))
+	assert.Empty(t, updates)
+
+	// drift is reverted
+	assert.NoError(t, ReconcileDashboardPermissions(client, 1, []v1beta1.Permission{
+		{Role: "Viewer", Permission: v1beta1.PermissionLevelView},
+		{Team: "platform", Permission: v1beta1.PermissionLevelAdmin},
+		{User: "jane@example.com", Permission: v1beta1.PermissionLevelEdit},
+	}, false))
+	assert.Equal(t, []grapi.PermissionItems{{Items: []*grapi.PermissionItem{
+		{Role: "Viewer", Permission: 1},
+		{TeamID: 7, Permission: 4},
+		{UserID: 9, Permission: 2},
+	}}}, updates)
+
+	assert.Error(t, ReconcileDashboardPermissions(client, 1, []v1beta1.Permission{
+		{Role: "Viewer", Team: "platform", Permission: v1beta1.PermissionLevelView},
+	}, false))
+	assert.Error(t, ReconcileDashboardPermissions(client, 1, []v1beta1.Permission{
+		{Team: "missing", Permission: v1beta1.PermissionLevelView},
+	}, false))
+}
+
+func TestReconcilePermissions_RemovesAllPermissions(t *testing.T) {
+	dashboardPermissions := []map[string]interface{}{
+		{"teamId": 7, "permission": 4},
+		{"role": "Viewer", "permission": 1, "inherited": true},
+	}
+	folderPermissions := []map[string]interface{}{
+		{"teamId": 7, "permission": 4},
+	}
+	updates := map[string][]grapi.PermissionItems{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		switch {
+		case r.URL.Path == "/api/dashboards/id/1/permissions" && r.Method == http.MethodGet:
+			response = dashboardPermissions
+		case r.URL.Path == "/api/folders/tenant/permissions" && r.Method == http.MethodGet:
+			response = folderPermissions
+		case r.Method == http.MethodPost:
+			var items grapi.PermissionItems
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&items))
+			updates[r.URL.Path] = append(updates[r.URL.Path], items)
+			response = map[string]interface{}{}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	defer ts.Close()
+
+	client, err := grapi.New(ts.URL, grapi.Config{})
+	assert.NoError(t, err)
+
+	// permissions that were never applied are left alone
+	assert.NoError(t, ReconcileDashboardPermissions(client, 1, nil, false))
+	assert.NoError(t, ReconcileFolderPermissions(client, "tenant", nil, false))
+	assert.Empty(t, updates)
+
+	// the dashboard inherits the permissions of its folder again
+	assert.NoError(t, ReconcileDashboardPermissions(client, 1, nil, true))
+	assert.Equal(t, []grapi.PermissionItems{{Items: []*grapi.PermissionItem{}}}, updates["/api/dashboards/id/1/permissions"])
+
+	// the folder gets the default permissions of Grafana
+	assert.NoError(t, ReconcileFolderPermissions(client, "tenant", nil, true))
+	assert.Equal(t, []grapi.PermissionItems{{Items: []*grapi.PermissionItem{
+		{Role: "Viewer", Permission: 1},
+		{Role: "Editor", Permission: 2},
+	}}}, updates["/api/folders/tenant/permissions"])
+}
@@ -108,18 +108,91 @@ func getVolumes(cr *v1beta1.Grafana, scheme *runtime.Scheme) []v1.Volume {
 		},
 	})
 
+	if cr.IsFileProvisioning() {
+		volumes = append(volumes, getProvisioningVolumes(cr, scheme)...)
+	}
+
 	return volumes
 }
 
+// getProvisioningVolumes returns the volumes with the provisioning files, they survive restarts of the pod
+func getProvisioningVolumes(cr *v1beta1.Grafana, scheme *runtime.Scheme) []v1.Volume {
+	providers := model.GetDashboardProvidersConfigMap(cr, scheme)
+	datasources := model.GetProvisionedDatasourcesSecret(cr, scheme)
+
+	// the dashboards are spread across several config maps that are projected into one directory
+	var dashboards []v1.VolumeProjection
+	for _, configMap := range model.GetProvisionedDashboardsConfigMaps(cr, scheme) {
+		dashboards = append(dashboards, v1.VolumeProjection{
+			ConfigMap: &v1.ConfigMapProjection{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: configMap.Name,
+				},
+			},
+		})
+	}
+
+	return []v1.Volume{
+		{
+			Name: config2.GrafanaProvisionProviderVolumeName,
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: providers.Name,
+					},
+				},
+			},
+		},
+		{
+			Name: config2.GrafanaProvisionDashboardVolumeName,
+			VolumeSource: v1.VolumeSource{
+				Projected: &v1.ProjectedVolumeSource{
+					Sources: dashboards,
+				},
+			},
+		},
+		{
+			Name: config2.GrafanaProvisionDatasourceVolumeName,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{
+					SecretName: datasources.Name,
+				},
+			},
+		},
+	}
+}
+
 func getVolumeMounts(cr *v1beta1.Grafana, scheme *runtime.Scheme) []v1.VolumeMount {
 	var mounts []v1.VolumeMount
 
 	config := model.GetGrafanaConfigMap(cr, scheme)
 
-	mounts = append(mounts, v1.VolumeMount{
-		Name:      config.Name,
-		MountPath: "/etc/grafana/",
-	})
+	if cr.IsFileProvisioning() {
+		// the provisioning files are mounted below /etc/grafana/, the config map volume is read only and can't
+		// contain their mount points
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      config.Name,
+			MountPath: "/etc/grafana/grafana.ini",
+			SubPath:   "grafana.ini",
+		})
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      config2.GrafanaProvisionProviderVolumeName,
+			MountPath: config2.GrafanaProvisioningDashboardsPath,
+		})
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      config2.GrafanaProvisionDashboardVolumeName,
+			MountPath: config2.GrafanaProvisionedDashboardsPath,
+		})
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      config2.GrafanaProvisionDatasourceVolumeName,
+			MountPath: config2.GrafanaProvisioningDatasourcesPath,
+		})
+	} else {
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      config.Name,
+			MountPath: "/etc/grafana/",
+		})
+	}
 
 	mounts = append(mounts, v1.VolumeMount{
 		Name:      config2.GrafanaDataVolumeName,
@@ -168,6 +241,14 @@ func getContainers(cr *v1beta1.Grafana, scheme *runtime.Scheme, vars *v1beta1.Op
 		Value: vars.Plugins,
 	})
 
+	// env var to restart container if provisioned datasources change, Grafana only reads them at startup
+	if cr.IsFileProvisioning() {
+		envVars = append(envVars, v1.EnvVar{
+			Name:  "DATASOURCES_HASH",
+			Value: vars.DatasourcesHash,
+		})
+	}
+
 	containers = append(containers, v1.Container{
 		Name:       "grafana",
 		Image:      image,
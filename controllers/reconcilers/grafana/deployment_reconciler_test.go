@@ -0,0 +1,46 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/config"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func Test_getVolumeMounts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1beta1.AddToScheme(scheme))
+
+	cr := &v1beta1.Grafana{
+		ObjectMeta: metav1.ObjectMeta{Name: "grafana", Namespace: "monitoring"},
+	}
+
+	mountPaths := func() map[string]string {
+		paths := map[string]string{}
+		for _, mount := range getVolumeMounts(cr, scheme) {
+			paths[mount.MountPath] = mount.Name
+		}
+		return paths
+	}
+
+	paths := mountPaths()
+	assert.Equal(t, "grafana-ini", paths["/etc/grafana/"])
+	assert.NotContains(t, paths, config.GrafanaProvisionedDashboardsPath)
+	assert.Len(t, getVolumes(cr, scheme), 3)
+
+	cr.Spec.ProvisioningMode = v1beta1.ProvisioningModeFile
+	paths = mountPaths()
+	assert.NotContains(t, paths, "/etc/grafana/")
+	assert.Equal(t, "grafana-ini", paths["/etc/grafana/grafana.ini"])
+	assert.Equal(t, config.GrafanaProvisionProviderVolumeName, paths[config.GrafanaProvisioningDashboardsPath])
+	assert.Equal(t, config.GrafanaProvisionDashboardVolumeName, paths[config.GrafanaProvisionedDashboardsPath])
+	assert.Equal(t, config.GrafanaProvisionDatasourceVolumeName, paths[config.GrafanaProvisioningDatasourcesPath])
+	assert.Len(t, getVolumes(cr, scheme), 6)
+
+	// external instances are always provisioned through the api
+	cr.Spec.External = &v1beta1.External{URL: "http://grafana"}
+	assert.False(t, cr.IsFileProvisioning())
+}
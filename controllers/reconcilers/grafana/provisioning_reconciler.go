@@ -0,0 +1,84 @@
+package grafana
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/config"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/model"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/reconcilers"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type ProvisioningReconciler struct {
+	client client.Client
+}
+
+func NewProvisioningReconciler(client client.Client) reconcilers.OperatorGrafanaReconciler {
+	return &ProvisioningReconciler{
+		client: client,
+	}
+}
+
+// Reconcile creates the config maps and the secret dashboards and datasources are provisioned from, their content is
+// written by the dashboard and datasource controllers
+func (r *ProvisioningReconciler) Reconcile(ctx context.Context, cr *v1beta1.Grafana, status *v1beta1.GrafanaStatus, vars *v1beta1.OperatorReconcileVars, scheme *runtime.Scheme) (v1beta1.OperatorStageStatus, error) {
+	logger := log.FromContext(ctx)
+
+	if !cr.IsFileProvisioning() {
+		logger.Info("skip creating provisioning files")
+		return v1beta1.OperatorStageResultSuccess, nil
+	}
+
+	providers := model.GetDashboardProvidersConfigMap(cr, scheme)
+	_, err := controllerutil.CreateOrUpdate(ctx, r.client, providers, func() error {
+		providers.Data = map[string]string{
+			config.DashboardProvidersFileName: config.GetDashboardProviders(),
+		}
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	for _, dashboards := range model.GetProvisionedDashboardsConfigMaps(cr, scheme) {
+		_, err = controllerutil.CreateOrUpdate(ctx, r.client, dashboards, func() error {
+			return nil
+		})
+		if err != nil {
+			return v1beta1.OperatorStageResultFailed, err
+		}
+	}
+
+	datasources := model.GetProvisionedDatasourcesSecret(cr, scheme)
+	_, err = controllerutil.CreateOrUpdate(ctx, r.client, datasources, func() error {
+		return nil
+	})
+	if err != nil {
+		return v1beta1.OperatorStageResultFailed, err
+	}
+
+	vars.DatasourcesHash = hashData(datasources.Data)
+	return v1beta1.OperatorStageResultSuccess, nil
+}
+
+func hashData(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write(data[key])
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
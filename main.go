@@ -34,16 +34,20 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	grafanav1beta1 "github.com/grafana-operator/grafana-operator-experimental/api/v1beta1"
 	"github.com/grafana-operator/grafana-operator-experimental/controllers"
 	"github.com/grafana-operator/grafana-operator-experimental/controllers/autodetect"
+	grafanaclient "github.com/grafana-operator/grafana-operator-experimental/controllers/client"
+	"github.com/grafana-operator/grafana-operator-experimental/controllers/fetchers"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -83,6 +87,11 @@ func main() {
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&fetchers.GrafanaComBaseUrl, "grafana-com-url", fetchers.GrafanaComBaseUrl, "The base url used to import dashboards from grafana.com.")
+	flag.Int64Var(&fetchers.MaxUrlResponseSize, "max-url-response-size", fetchers.MaxUrlResponseSize, "The maximum size in bytes of dashboards and jsonnet projects fetched from urls and of unpacked jsonnet projects, 0 disables the limit.")
+	flag.DurationVar(&fetchers.UrlRequestTimeout, "url-request-timeout", fetchers.UrlRequestTimeout, "The maximum duration of a single request for dashboards and jsonnet projects fetched from urls, 0 disables the timeout.")
+	flag.IntVar(&fetchers.MaxStatusContentCacheSize, "max-status-content-cache-size", fetchers.MaxStatusContentCacheSize, "The size in bytes above which the content cache of dashboards fetched from urls is stored in config maps instead of the status, 0 always stores it in the status.")
+	flag.DurationVar(&grafanaclient.InventoryTTL, "grafana-inventory-ttl", grafanaclient.InventoryTTL, "How long the dashboards and folders listed from a Grafana instance are reused before they are listed again.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -104,6 +113,8 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "f75f3bba.integreatly.org",
+		// namespaces are read directly, a namespace scoped operator can't list them and a cache for them would never sync
+		ClientDisableCacheFor: []client.Object{&corev1.Namespace{}},
 	}
 
 	switch {
@@ -151,21 +162,32 @@ func main() {
 		os.Exit(1)
 	}
 	if err = (&controllers.GrafanaDashboardReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Log:    ctrl.Log,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      ctrl.Log,
+		Recorder: mgr.GetEventRecorderFor("grafanadashboard-controller"),
 	}).SetupWithManager(mgr, ctx); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaDashboard")
 		os.Exit(1)
 	}
 	if err = (&controllers.GrafanaDatasourceReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Log:    ctrl.Log,
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Log:      ctrl.Log,
+		Recorder: mgr.GetEventRecorderFor("grafanadatasource-controller"),
 	}).SetupWithManager(mgr, ctx); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaDatasource")
 		os.Exit(1)
 	}
+	if err = (&controllers.GrafanaDatasourceTemplateReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Log:           ctrl.Log,
+		ClusterScoped: watchNamespace == "",
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GrafanaDatasourceTemplate")
+		os.Exit(1)
+	}
 	if err = (&controllers.GrafanaFolderReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -173,6 +195,22 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "GrafanaFolder")
 		os.Exit(1)
 	}
+	if err = (&controllers.GrafanaLibraryPanelReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Log:    ctrl.Log,
+	}).SetupWithManager(mgr, ctx); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GrafanaLibraryPanel")
+		os.Exit(1)
+	}
+	if err = (&controllers.GrafanaDashboardExportReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Log:    ctrl.Log,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GrafanaDashboardExport")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {